@@ -4,14 +4,16 @@ import "github.com/watchs/domain/entity"
 
 // ConfigApplicationService 定义配置应用服务接口
 type ConfigApplicationService interface {
-	// LoadOrCreateConfig 加载或创建配置
-	LoadOrCreateConfig(configPath, watchDir, fileTypes, excludePaths, command string) (*entity.WatchConfig, error)
+	// LoadOrCreateConfig 加载或创建配置，profile 不为空时应用配置文件中的该配置方案
+	LoadOrCreateConfig(configPath, profile, watchDir, fileTypes, excludePaths, command string) (*entity.WatchConfig, error)
 	// SaveConfig 保存配置
 	SaveConfig(config *entity.WatchConfig, configPath string) error
 	// InitializeConfig 初始化配置文件
 	InitializeConfig(params *InitConfigParams) error
 	// RunInteractiveConfig 运行交互式配置向导
 	RunInteractiveConfig() (*entity.WatchConfig, string, error)
+	// ValidateConfig 检查配置文件，返回其中的所有问题
+	ValidateConfig(configPath string) ([]entity.ConfigProblem, error)
 }
 
 // InitConfigParams 初始化配置参数
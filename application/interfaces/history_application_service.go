@@ -0,0 +1,27 @@
+package interfaces
+
+import (
+	"time"
+
+	"github.com/watchs/domain/entity"
+)
+
+// HistoryApplicationService 定义执行历史应用服务接口
+type HistoryApplicationService interface {
+	// ListRuns 返回满足条件的执行记录，按开始时间从新到旧排列
+	ListRuns(query *HistoryQuery) ([]*entity.RunResult, error)
+	// GetRun 返回指定ID的执行记录
+	GetRun(watchDir, runID string) (*entity.RunResult, error)
+}
+
+// HistoryQuery 执行历史查询参数
+type HistoryQuery struct {
+	// 监控目录，执行历史保存在其中的 .watchs 目录下
+	WatchDir string
+	// 只返回失败（包括超时）的执行
+	FailedOnly bool
+	// 只返回该时间之后开始的执行，零值表示不限制
+	Since time.Time
+	// 最多返回的记录数，0 表示不限制
+	Limit int
+}
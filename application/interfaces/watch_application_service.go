@@ -17,11 +17,18 @@ type WatchApplicationService interface {
 // WatchConfig 监控配置参数
 type WatchConfig struct {
 	ConfigPath     string
+	Profile        string
 	WatchDir       string
 	FileTypes      string
 	ExcludePaths   string
 	Command        string
 	DebounceMs     int
+	Backend        string
+	PollIntervalMs int
+	OnBusy         string
+	Supervise      bool
+	PTY            bool
+	ForwardStdin   bool
 	ShowMemory     bool
 	MemoryInterval int
 }
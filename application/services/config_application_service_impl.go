@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -15,29 +16,45 @@ import (
 
 // ConfigApplicationServiceImpl 配置应用服务实现
 type ConfigApplicationServiceImpl struct {
-	configRepo repository.ConfigRepository
+	configRepo     repository.ConfigRepository
+	userConfigPath string
 }
 
-// NewConfigApplicationService 创建配置应用服务
-func NewConfigApplicationService(configRepo repository.ConfigRepository) interfaces.ConfigApplicationService {
+// NewConfigApplicationService 创建配置应用服务，userConfigPath 为用户级全局配置文件，为空表示没有全局配置
+func NewConfigApplicationService(configRepo repository.ConfigRepository, userConfigPath string) interfaces.ConfigApplicationService {
 	return &ConfigApplicationServiceImpl{
-		configRepo: configRepo,
+		configRepo:     configRepo,
+		userConfigPath: userConfigPath,
 	}
 }
 
-// LoadOrCreateConfig 加载或创建配置
-func (s *ConfigApplicationServiceImpl) LoadOrCreateConfig(configPath, watchDir, fileTypes, excludePaths, command string) (*entity.WatchConfig, error) {
+// LoadOrCreateConfig 加载或创建配置，用户级全局配置作为默认值
+// profile 不为空时先应用该配置方案，再用命令行参数覆盖
+func (s *ConfigApplicationServiceImpl) LoadOrCreateConfig(configPath, profile, watchDir, fileTypes, excludePaths, command string) (*entity.WatchConfig, error) {
 	// 尝试加载配置
 	config, err := s.configRepo.LoadConfig(configPath)
 	if err != nil {
-		// 如果配置文件不存在，尝试使用命令行参数
-		if os.IsNotExist(err) && watchDir != "" && command != "" {
+		// 如果配置文件不存在，尝试使用命令行参数，配置方案只能定义在配置文件中
+		if errors.Is(err, os.ErrNotExist) && watchDir != "" && command != "" && profile == "" {
 			log.Printf("配置文件 %s 不存在，使用命令行参数", configPath)
-			return s.createConfigFromArgs(watchDir, fileTypes, excludePaths, command)
+			if config, err = s.createConfigFromArgs(watchDir, fileTypes, excludePaths, command); err != nil {
+				return nil, err
+			}
+			return s.applyUserConfig(config)
 		}
 		return nil, err
 	}
 
+	if config, err = s.applyUserConfig(config); err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		if config, err = s.applyProfile(config, profile); err != nil {
+			return nil, err
+		}
+	}
+
 	// 命令行参数覆盖配置文件
 	if watchDir != "" || fileTypes != "" || excludePaths != "" || command != "" {
 		return s.overrideConfig(config, watchDir, fileTypes, excludePaths, command)
@@ -97,14 +114,26 @@ func (s *ConfigApplicationServiceImpl) RunInteractiveConfig() (*entity.WatchConf
 	return interactiveCLI.Run()
 }
 
+// ValidateConfig 检查配置文件，返回其中的所有问题
+func (s *ConfigApplicationServiceImpl) ValidateConfig(configPath string) ([]entity.ConfigProblem, error) {
+	return s.configRepo.CheckConfig(configPath)
+}
+
 // createConfigFromArgs 从命令行参数创建配置
 func (s *ConfigApplicationServiceImpl) createConfigFromArgs(watchDir, fileTypes, excludePaths, command string) (*entity.WatchConfig, error) {
-	return entity.NewWatchConfig(
+	config, err := entity.NewWatchConfig(
 		watchDir,
 		s.parseCommaSeparated(fileTypes),
 		s.parseCommaSeparated(excludePaths),
 		command,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
 // overrideConfig 用命令行参数覆盖配置
@@ -129,7 +158,139 @@ func (s *ConfigApplicationServiceImpl) overrideConfig(config *entity.WatchConfig
 		newCommand = command
 	}
 
-	return entity.NewWatchConfig(newWatchDir, newFileTypes, newExcludePaths, newCommand)
+	overridden, err := entity.NewWatchConfig(newWatchDir, newFileTypes, newExcludePaths, newCommand)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保留配置文件中命令行无法覆盖的其他设置
+	result := *config
+	result.WatchDir = overridden.WatchDir
+	result.FileTypes = overridden.FileTypes
+	result.ExcludePaths = overridden.ExcludePaths
+	// 命令行指定的排除路径相对于当前目录
+	if excludePaths != "" {
+		result.BaseDir = ""
+	}
+	result.Command = overridden.Command
+
+	// 命令行指定的命令代替配置文件中的任务流水线
+	if command != "" {
+		result.Tasks = nil
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// applyUserConfig 将用户级全局配置作为默认值合并到配置中，配置中已设置的配置项优先
+func (s *ConfigApplicationServiceImpl) applyUserConfig(config *entity.WatchConfig) (*entity.WatchConfig, error) {
+	if s.userConfigPath == "" {
+		return config, nil
+	}
+	userConfig, err := s.configRepo.LoadUserConfig(s.userConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("加载用户配置 %s 失败: %w", s.userConfigPath, err)
+	}
+
+	result := *config
+	result.CommandOptions = userConfig.CommandOptions.Merge(config.CommandOptions)
+	result.Hooks = userConfig.Hooks.Merge(config.Hooks)
+	if result.Color == nil {
+		result.Color = userConfig.Color
+	}
+	if result.Debounce == 0 {
+		result.Debounce = userConfig.Debounce
+	}
+	// 项目配置中明确设置的监控后端（包括 auto）优先
+	if result.Backend == entity.BackendDefault {
+		result.Backend = userConfig.Backend
+	}
+	if result.PollInterval == 0 {
+		result.PollInterval = userConfig.PollInterval
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("用户配置 %s 与项目配置合并后无效: %w", s.userConfigPath, err)
+	}
+	return &result, nil
+}
+
+// applyProfile 用配置方案中设置的配置项覆盖基础配置
+func (s *ConfigApplicationServiceImpl) applyProfile(config *entity.WatchConfig, name string) (*entity.WatchConfig, error) {
+	profile, ok := config.Profiles[name]
+	if !ok {
+		names := config.ProfileNames()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("配置方案 %s 不存在，配置文件中没有定义配置方案", name)
+		}
+		return nil, fmt.Errorf("配置方案 %s 不存在，可选值: %s", name, strings.Join(names, "、"))
+	}
+
+	result := *config
+	if profile.WatchDir != "" {
+		// 与配置文件中的其他相对路径一样基于配置文件所在的目录
+		resolved, err := entity.NewWatchConfig(entity.ResolvePath(config.BaseDir, profile.WatchDir), nil, nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("配置方案 %s: %w", name, err)
+		}
+		result.WatchDir = resolved.WatchDir
+	}
+	if profile.FileTypes != nil {
+		result.FileTypes = profile.FileTypes
+	}
+	if profile.ExcludePaths != nil {
+		result.ExcludePaths = profile.ExcludePaths
+	}
+	if profile.Include != nil {
+		result.Include = profile.Include
+	}
+	if profile.Exclude != nil {
+		result.Exclude = profile.Exclude
+	}
+
+	// 配置方案中的命令代替基础配置中的任务流水线，与命令行参数的覆盖方式一致
+	if profile.Command != "" {
+		result.Command = profile.Command
+		result.Tasks = nil
+	}
+	if profile.Tasks != nil {
+		result.Tasks = profile.Tasks
+	}
+	if profile.Rules != nil {
+		result.Rules = profile.Rules
+	}
+
+	result.CommandOptions = config.CommandOptions.Merge(profile.CommandOptions)
+	result.Hooks = config.Hooks.Merge(profile.Hooks)
+	if profile.ForwardStdin != nil {
+		result.ForwardStdin = *profile.ForwardStdin
+	}
+	if profile.StdinEscape != "" {
+		result.StdinEscape = profile.StdinEscape
+	}
+	if profile.UseGitignore != nil {
+		result.UseGitignore = *profile.UseGitignore
+	}
+	if profile.Backend != "" {
+		result.Backend = profile.Backend
+	}
+	if profile.PollInterval > 0 {
+		result.PollInterval = profile.PollInterval
+	}
+	if profile.Debounce > 0 {
+		result.Debounce = profile.Debounce
+	}
+	if profile.Color != nil {
+		result.Color = profile.Color
+	}
+
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("配置方案 %s: %w", name, err)
+	}
+	return &result, nil
 }
 
 // parseCommaSeparated 解析逗号分隔的字符串
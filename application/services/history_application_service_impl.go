@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/watchs/application/interfaces"
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/domain/repository"
+)
+
+// HistoryApplicationServiceImpl 执行历史应用服务实现
+type HistoryApplicationServiceImpl struct {
+	historyRepo repository.RunHistoryRepository
+}
+
+// NewHistoryApplicationService 创建执行历史应用服务
+func NewHistoryApplicationService(historyRepo repository.RunHistoryRepository) interfaces.HistoryApplicationService {
+	return &HistoryApplicationServiceImpl{
+		historyRepo: historyRepo,
+	}
+}
+
+// ListRuns 返回满足条件的执行记录，按开始时间从新到旧排列
+func (s *HistoryApplicationServiceImpl) ListRuns(query *interfaces.HistoryQuery) ([]*entity.RunResult, error) {
+	results, err := s.loadRuns(query.WatchDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*entity.RunResult
+	for i := len(results) - 1; i >= 0; i-- {
+		result := results[i]
+		if query.FailedOnly && (result.Succeeded() || result.Terminated) {
+			continue
+		}
+		if !query.Since.IsZero() && result.StartTime.Before(query.Since) {
+			continue
+		}
+		matched = append(matched, result)
+		if query.Limit > 0 && len(matched) >= query.Limit {
+			break
+		}
+	}
+	return matched, nil
+}
+
+// GetRun 返回指定ID的执行记录
+func (s *HistoryApplicationServiceImpl) GetRun(watchDir, runID string) (*entity.RunResult, error) {
+	results, err := s.loadRuns(watchDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i].RunID == runID {
+			return results[i], nil
+		}
+	}
+	return nil, fmt.Errorf("执行记录不存在: %s", runID)
+}
+
+// loadRuns 读取监控目录的全部执行历史
+func (s *HistoryApplicationServiceImpl) loadRuns(watchDir string) ([]*entity.RunResult, error) {
+	absDir, err := filepath.Abs(watchDir)
+	if err != nil {
+		return nil, fmt.Errorf("获取绝对路径失败: %w", err)
+	}
+	return s.historyRepo.List(filepath.Join(absDir, entity.StateDirName))
+}
@@ -4,29 +4,40 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/watchs/application"
 	"github.com/watchs/application/interfaces"
 	"github.com/watchs/domain/entity"
+	"github.com/watchs/domain/repository"
+	"github.com/watchs/domain/service"
 	"github.com/watchs/infrastructure/ui"
 	"github.com/watchs/infrastructure/utils"
 	"github.com/watchs/infrastructure/watcher"
 )
 
+// mainCommandName 主命令在输出前缀和日志文件名中使用的名称
+const mainCommandName = "main"
+
 // WatchApplicationServiceImpl 监控应用服务实现
 type WatchApplicationServiceImpl struct {
 	configService interfaces.ConfigApplicationService
+	historyRepo   repository.RunHistoryRepository
 	watchService  *application.WatchService
+	config        *entity.WatchConfig
+	configWatcher *watcher.ConfigFileWatcher
+	stdin         *watcher.StdinForwarder
 	isRunning     bool
 	memoryStopCh  chan struct{}
 }
 
 // NewWatchApplicationService 创建监控应用服务
-func NewWatchApplicationService(configService interfaces.ConfigApplicationService) interfaces.WatchApplicationService {
+func NewWatchApplicationService(configService interfaces.ConfigApplicationService, historyRepo repository.RunHistoryRepository) interfaces.WatchApplicationService {
 	return &WatchApplicationServiceImpl{
 		configService: configService,
+		historyRepo:   historyRepo,
 		isRunning:     false,
 	}
 }
@@ -38,16 +49,17 @@ func (s *WatchApplicationServiceImpl) StartWatch(params *interfaces.WatchConfig)
 	}
 
 	// 加载或创建配置
-	config, err := s.configService.LoadOrCreateConfig(
-		params.ConfigPath,
-		params.WatchDir,
-		params.FileTypes,
-		params.ExcludePaths,
-		params.Command,
-	)
+	config, err := s.loadConfig(params)
 	if err != nil {
-		ui.PrintError(fmt.Sprintf("配置加载失败: %v", err))
-		return fmt.Errorf("配置加载失败: %v", err)
+		ui.PrintError(err.Error())
+		return err
+	}
+	ui.SetColorEnabled(config.ColorEnabled())
+	if _, err := os.Stat(params.ConfigPath); err == nil {
+		ui.PrintInfo(fmt.Sprintf("使用配置文件: %s", params.ConfigPath))
+	}
+	if params.Profile != "" {
+		ui.PrintInfo(fmt.Sprintf("使用配置方案: %s", params.Profile))
 	}
 
 	ui.PrintInfo("正在初始化监控服务...")
@@ -55,18 +67,36 @@ func (s *WatchApplicationServiceImpl) StartWatch(params *interfaces.WatchConfig)
 	// 模拟加载动画
 	ui.SimulateLoading(2*time.Second, "初始化监控器")
 
+	// 配置文件位于监控目录中时，保存配置文件只触发重新加载，不再执行命令
+	if _, err := os.Stat(params.ConfigPath); err == nil {
+		if absPath, err := filepath.Abs(params.ConfigPath); err == nil {
+			config.ConfigFile = absPath
+		}
+	}
+
 	// 创建文件监控服务
-	fsWatcher, err := watcher.NewFSNotifyWatcher(config)
+	fsWatcher, err := watcher.NewWatcherService(config)
 	if err != nil {
 		ui.PrintError(fmt.Sprintf("创建文件监控器失败: %v", err))
 		return fmt.Errorf("创建文件监控器失败: %v", err)
 	}
 
-	// 创建命令执行器
-	cmdExecutor := watcher.NewCommandExecutor(params.DebounceMs)
+	// 转发标准输入时，转义命令可以重新执行命令或停止监控
+	quitCh := make(chan struct{}, 1)
+	s.stdin = nil
+	if config.ForwardStdin {
+		s.stdin = watcher.NewStdinForwarder(config.Escape(), func() {
+			s.watchService.Rerun()
+		}, func() {
+			select {
+			case quitCh <- struct{}{}:
+			default:
+			}
+		})
+	}
 
 	// 创建应用服务
-	s.watchService = application.NewWatchService(config, fsWatcher, cmdExecutor)
+	s.watchService = application.NewWatchService(config, fsWatcher, s.newCommandExecutor, watcher.NewHookRunner(config.CommandOptions), s.historyRepo)
 
 	// 启动监控
 	if err := s.watchService.Start(); err != nil {
@@ -75,8 +105,18 @@ func (s *WatchApplicationServiceImpl) StartWatch(params *interfaces.WatchConfig)
 	}
 
 	s.isRunning = true
+	s.config = config
 	ui.PrintSuccess(fmt.Sprintf("监控已启动，正在监控目录: %s", config.WatchDir))
 
+	// 配置文件变化后自动重新加载，仅使用命令行参数时没有可监控的配置文件
+	s.configWatcher = nil
+	if config.ConfigFile != "" {
+		s.configWatcher = watcher.NewConfigFileWatcher(params.ConfigPath, func() {
+			s.reloadConfig(params)
+		})
+		s.configWatcher.Start()
+	}
+
 	// 显示启动时的内存信息
 	startStats := utils.GetMemoryStats()
 	utils.PrintMemoryStats(startStats)
@@ -93,13 +133,19 @@ func (s *WatchApplicationServiceImpl) StartWatch(params *interfaces.WatchConfig)
 	}
 
 	ui.PrintInfo("按 Ctrl+C 停止监控...")
+	if s.stdin != nil {
+		s.stdin.Start()
+	}
 
 	// 等待中断信号
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
-	// 等待信号
-	<-sigCh
+	// 等待信号或停止监控的转义命令
+	select {
+	case <-sigCh:
+	case <-quitCh:
+	}
 
 	// 清理信号处理器，防止内存泄漏
 	signal.Stop(sigCh)
@@ -108,6 +154,105 @@ func (s *WatchApplicationServiceImpl) StartWatch(params *interfaces.WatchConfig)
 	return s.StopWatch()
 }
 
+// loadConfig 加载配置文件，并用命令行参数覆盖其中的设置
+func (s *WatchApplicationServiceImpl) loadConfig(params *interfaces.WatchConfig) (*entity.WatchConfig, error) {
+	config, err := s.configService.LoadOrCreateConfig(
+		params.ConfigPath,
+		params.Profile,
+		params.WatchDir,
+		params.FileTypes,
+		params.ExcludePaths,
+		params.Command,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("配置加载失败: %v", err)
+	}
+
+	// 命令行指定的监控后端和执行策略覆盖配置文件
+	if params.Backend != "" {
+		config.Backend = entity.WatcherBackend(params.Backend)
+	}
+	if params.PollIntervalMs > 0 {
+		config.PollInterval = time.Duration(params.PollIntervalMs) * time.Millisecond
+	}
+	if params.OnBusy != "" {
+		config.OnBusy = entity.BusyPolicy(params.OnBusy)
+	}
+	if params.Supervise {
+		config.Supervise = &params.Supervise
+	}
+	if params.PTY {
+		config.PTY = &params.PTY
+	}
+	if params.ForwardStdin {
+		config.ForwardStdin = true
+	}
+
+	if params.DebounceMs > 0 {
+		config.Debounce = time.Duration(params.DebounceMs) * time.Millisecond
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("配置校验失败: %v", err)
+	}
+	return config, nil
+}
+
+// reloadConfig 在配置文件变化后重新加载配置并切换监控目录、过滤规则和命令
+// 新配置无效或切换失败时输出错误，旧配置继续运行
+func (s *WatchApplicationServiceImpl) reloadConfig(params *interfaces.WatchConfig) {
+	ui.PrintInfo(fmt.Sprintf("配置文件 %s 已修改，重新加载配置...", params.ConfigPath))
+
+	config, err := s.loadConfig(params)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("%v，继续使用原配置", err))
+		return
+	}
+
+	// 监控后端和标准输入转发在启动时确定，修改后需要重新启动 watchs
+	if config.Backend != s.config.Backend || config.ForwardStdin != s.config.ForwardStdin || config.Escape() != s.config.Escape() {
+		ui.PrintWarning("修改 backend、forward_stdin 和 stdin_escape 需要重新启动 watchs 才能生效")
+		config.Backend = s.config.Backend
+		config.ForwardStdin = s.config.ForwardStdin
+		config.StdinEscape = s.config.StdinEscape
+		if err := config.Validate(); err != nil {
+			ui.PrintError(fmt.Sprintf("配置校验失败: %v，继续使用原配置", err))
+			return
+		}
+	}
+
+	config.ConfigFile = s.config.ConfigFile
+	if err := s.watchService.Reload(config, watcher.NewHookRunner(config.CommandOptions)); err != nil {
+		ui.PrintError(fmt.Sprintf("重新加载配置失败: %v，继续使用原配置", err))
+		return
+	}
+	s.config = config
+	ui.SetColorEnabled(config.ColorEnabled())
+	ui.PrintSuccess(fmt.Sprintf("配置已重新加载，正在监控目录: %s", config.WatchDir))
+}
+
+// newCommandExecutor 为主命令或路由规则创建命令执行器，主命令配置了任务流水线时使用任务执行器
+// 转发标准输入时，输入只连接到主命令
+func (s *WatchApplicationServiceImpl) newCommandExecutor(config *entity.WatchConfig, rule *entity.Rule) (service.CommandExecutor, error) {
+	options := config.OptionsFor(rule)
+	if rule == nil && len(config.Tasks) > 0 {
+		taskRunner, err := watcher.NewTaskRunner(config.Tasks, options)
+		if err != nil {
+			return nil, fmt.Errorf("创建任务流水线失败: %w", err)
+		}
+		return taskRunner, nil
+	}
+	name := mainCommandName
+	if rule != nil {
+		name = rule.DisplayName()
+	}
+	executor := watcher.NewCommandExecutor(name, options)
+	if rule == nil && s.stdin != nil {
+		executor.ForwardInput(s.stdin)
+	}
+	return executor, nil
+}
+
 // StopWatch 停止文件监控
 func (s *WatchApplicationServiceImpl) StopWatch() error {
 	if !s.isRunning {
@@ -116,6 +261,12 @@ func (s *WatchApplicationServiceImpl) StopWatch() error {
 
 	ui.PrintWarning("正在关闭监控...")
 
+	// 先停止配置文件监控，等待正在进行的重新加载结束
+	if s.configWatcher != nil {
+		s.configWatcher.Stop()
+		s.configWatcher = nil
+	}
+
 	// 停止内存监控（如果启用）
 	if s.memoryStopCh != nil {
 		close(s.memoryStopCh)
@@ -139,7 +290,7 @@ func (s *WatchApplicationServiceImpl) StopWatch() error {
 
 // CreateWatchConfigFromArgs 从命令行参数创建监控配置
 func (s *WatchApplicationServiceImpl) CreateWatchConfigFromArgs(watchDir, fileTypes, excludePaths, command string) (*entity.WatchConfig, error) {
-	return s.configService.LoadOrCreateConfig("", watchDir, fileTypes, excludePaths, command)
+	return s.configService.LoadOrCreateConfig("", "", watchDir, fileTypes, excludePaths, command)
 }
 
 // IsRunning 检查监控是否正在运行
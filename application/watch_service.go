@@ -2,32 +2,60 @@ package application
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/watchs/domain/entity"
+	"github.com/watchs/domain/repository"
 	"github.com/watchs/domain/service"
 	"github.com/watchs/infrastructure/ui"
 )
 
+// defaultDebounce 默认防抖静默期
+const defaultDebounce = 500 * time.Millisecond
+
+// ExecutorFactory 创建命令执行器，rule 为 nil 时为主命令创建
+type ExecutorFactory func(config *entity.WatchConfig, rule *entity.Rule) (service.CommandExecutor, error)
+
 // WatchService 是应用层的文件监控服务
 type WatchService struct {
-	config          *entity.WatchConfig
-	watcherService  service.WatcherService
+	config         *entity.WatchConfig
+	watcherService service.WatcherService
+	newExecutor    ExecutorFactory
+	hookRunner     service.HookRunner
+	history        repository.RunHistoryRepository
+	isRunning      bool
+
+	// 主命令的执行器，未配置主命令时为 nil
 	commandExecutor service.CommandExecutor
-	isRunning       bool
+	// 与 config.Rules 一一对应的规则执行器
+	ruleExecutors []service.CommandExecutor
+
+	// 保证执行命令和重新加载配置不会同时进行
+	// 配置、钩子执行器和命令执行器在同时持有 runMu 和 mu 时才会被替换
+	runMu sync.Mutex
+
+	// 防抖相关状态，由 mu 保护
+	mu            sync.Mutex
+	pendingEvents []*entity.FileEvent
+	debounceTimer *time.Timer
 }
 
 // NewWatchService 创建一个新的应用层文件监控服务
 func NewWatchService(
 	config *entity.WatchConfig,
 	watcherService service.WatcherService,
-	commandExecutor service.CommandExecutor,
+	newExecutor ExecutorFactory,
+	hookRunner service.HookRunner,
+	history repository.RunHistoryRepository,
 ) *WatchService {
 	return &WatchService{
-		config:          config,
-		watcherService:  watcherService,
-		commandExecutor: commandExecutor,
-		isRunning:       false,
+		config:         config,
+		watcherService: watcherService,
+		newExecutor:    newExecutor,
+		hookRunner:     hookRunner,
+		history:        history,
+		isRunning:      false,
 	}
 }
 
@@ -36,15 +64,20 @@ func (s *WatchService) Start() error {
 	if s.isRunning {
 		return nil
 	}
+
+	// 为主命令和每条规则创建执行器
+	if err := s.createExecutors(); err != nil {
+		ui.PrintError(fmt.Sprintf("创建命令执行器失败: %v", err))
+		return err
+	}
+
 	s.isRunning = true
 
 	// 注册文件事件处理器
 	s.watcherService.OnFileEvent(func(event *entity.FileEvent) error {
 		// 只处理写入、创建和删除事件
 		if event.Type == entity.EventWrite || event.Type == entity.EventCreate || event.Type == entity.EventRemove {
-			// 延迟执行，避免文件正在写入
-			time.Sleep(100 * time.Millisecond)
-			return s.commandExecutor.Execute(s.config.Command, s.config.WatchDir)
+			s.scheduleEvent(event)
 		}
 		return nil
 	})
@@ -56,36 +89,310 @@ func (s *WatchService) Start() error {
 		return err
 	}
 
+	s.runHook(entity.HookOnStart, nil)
+
 	// 执行初始命令
 	ui.PrintInfo("执行初始命令...")
-	if err := s.commandExecutor.Execute(s.config.Command, s.config.WatchDir); err != nil {
-		ui.PrintWarning(fmt.Sprintf("执行初始命令失败: %v", err))
+	s.runAll()
+
+	return nil
+}
+
+// Rerun 不等待文件变化，立即重新执行主命令和所有规则的命令
+func (s *WatchService) Rerun() {
+	if !s.running() {
+		return
+	}
+	ui.PrintInfo("重新执行命令...")
+	s.runAll()
+}
+
+// Reload 在不停止监控的情况下切换到新的配置
+// 监控目录和过滤规则切换成功后才替换命令执行器并重新执行命令，任何一步失败都保留旧配置继续运行
+// 切换期间收到的文件事件留在当前批次中，由新的执行器处理
+func (s *WatchService) Reload(config *entity.WatchConfig, hookRunner service.HookRunner) error {
+	s.runMu.Lock()
+	if !s.running() {
+		s.runMu.Unlock()
+		return fmt.Errorf("监控未运行")
 	}
 
+	commandExecutor, ruleExecutors, err := s.buildExecutors(config)
+	if err != nil {
+		s.runMu.Unlock()
+		return fmt.Errorf("创建命令执行器失败: %w", err)
+	}
+
+	if err := s.watcherService.UpdateConfig(config); err != nil {
+		s.runMu.Unlock()
+		closeExecutors(append([]service.CommandExecutor{commandExecutor}, ruleExecutors...))
+		return fmt.Errorf("切换监控目录失败: %w", err)
+	}
+
+	old := s.executors()
+	s.mu.Lock()
+	s.config = config
+	s.hookRunner = hookRunner
+	s.commandExecutor = commandExecutor
+	s.ruleExecutors = ruleExecutors
+	s.mu.Unlock()
+
+	terminateExecutors(old)
+	closeExecutors(old)
+	s.runMu.Unlock()
+
+	ui.PrintInfo("重新执行命令...")
+	s.runAll()
 	return nil
 }
 
+// runAll 执行主命令和所有规则的命令，监控已停止时不再执行
+func (s *WatchService) runAll() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if !s.running() {
+		return
+	}
+
+	if s.commandExecutor != nil {
+		if err := s.commandExecutor.Execute(s.config.Command, s.config.WatchDir, nil); err != nil {
+			ui.PrintWarning(fmt.Sprintf("执行命令失败: %v", err))
+		}
+	}
+	for i, executor := range s.ruleExecutors {
+		rule := &s.config.Rules[i]
+		if err := executor.Execute(rule.Command, s.config.WatchDir, nil); err != nil {
+			ui.PrintWarning(fmt.Sprintf("执行规则 %s 的命令失败: %v", rule.DisplayName(), err))
+		}
+	}
+}
+
 // Stop 停止监控
 func (s *WatchService) Stop() error {
 	if !s.isRunning {
 		return nil
 	}
+
+	// 丢弃尚未触发的事件
+	s.mu.Lock()
 	s.isRunning = false
+	if s.debounceTimer != nil {
+		s.debounceTimer.Stop()
+		s.debounceTimer = nil
+	}
+	s.pendingEvents = nil
+	s.mu.Unlock()
+
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
 
 	// 终止命令
-	if err := s.commandExecutor.Terminate(); err != nil {
-		ui.PrintWarning(fmt.Sprintf("终止命令失败: %v", err))
-	}
+	terminateExecutors(s.executors())
 
 	// 停止监控服务
 	err := s.watcherService.Stop()
 
-	// 清理命令执行器资源（如果实现了Close方法）
-	if closer, ok := s.commandExecutor.(interface{ Close() error }); ok {
-		if closeErr := closer.Close(); closeErr != nil {
-			ui.PrintWarning(fmt.Sprintf("清理命令执行器失败: %v", closeErr))
+	s.runHook(entity.HookOnStop, nil)
+
+	// 清理命令执行器资源
+	closeExecutors(s.executors())
+
+	return err
+}
+
+// createExecutors 为主命令和每条规则创建执行器
+func (s *WatchService) createExecutors() error {
+	commandExecutor, ruleExecutors, err := s.buildExecutors(s.config)
+	if err != nil {
+		return err
+	}
+	s.commandExecutor = commandExecutor
+	s.ruleExecutors = ruleExecutors
+	return nil
+}
+
+// buildExecutors 按配置创建主命令的执行器（未配置主命令时为 nil）和与 config.Rules 一一对应的规则执行器
+func (s *WatchService) buildExecutors(config *entity.WatchConfig) (service.CommandExecutor, []service.CommandExecutor, error) {
+	var commandExecutor service.CommandExecutor
+	if config.HasMainCommand() {
+		executor, err := s.newExecutor(config, nil)
+		if err != nil {
+			return nil, nil, err
 		}
+		commandExecutor = executor
 	}
 
-	return err
+	var ruleExecutors []service.CommandExecutor
+	for i := range config.Rules {
+		executor, err := s.newExecutor(config, &config.Rules[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		ruleExecutors = append(ruleExecutors, executor)
+	}
+
+	executors := append([]service.CommandExecutor{commandExecutor}, ruleExecutors...)
+	for _, executor := range executors {
+		if executor == nil {
+			continue
+		}
+		executor.OnRunComplete(s.handleRunResult)
+		executor.OnReady(func(result *entity.RunResult) {
+			s.runHook(entity.HookOnReady, result)
+		})
+	}
+	return commandExecutor, ruleExecutors, nil
+}
+
+// terminateExecutors 终止执行器中正在执行的命令
+func terminateExecutors(executors []service.CommandExecutor) {
+	for _, executor := range executors {
+		if err := executor.Terminate(); err != nil {
+			ui.PrintWarning(fmt.Sprintf("终止命令失败: %v", err))
+		}
+	}
+}
+
+// closeExecutors 清理命令执行器资源（如果实现了Close方法）
+func closeExecutors(executors []service.CommandExecutor) {
+	for _, executor := range executors {
+		if closer, ok := executor.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				ui.PrintWarning(fmt.Sprintf("清理命令执行器失败: %v", err))
+			}
+		}
+	}
+}
+
+// handleRunResult 在命令执行结束后记录执行历史，并按结果执行 on_success 或 on_failure 钩子
+// 被 watchs 主动终止的执行（重启、停止监控）只记录历史，不触发钩子
+func (s *WatchService) handleRunResult(result *entity.RunResult) {
+	config, _ := s.current()
+	if s.history != nil {
+		if err := s.history.Append(config.StateDir(), result); err != nil {
+			ui.PrintWarning(fmt.Sprintf("记录执行历史失败: %v", err))
+		}
+	}
+
+	if result.Terminated {
+		return
+	}
+	if result.Succeeded() {
+		s.runHook(entity.HookOnSuccess, result)
+	} else {
+		s.runHook(entity.HookOnFailure, result)
+	}
+}
+
+// runHook 执行配置的钩子命令，钩子失败只输出警告
+func (s *WatchService) runHook(hook entity.HookType, result *entity.RunResult) {
+	config, hookRunner := s.current()
+	command := config.Hooks.Command(hook)
+	if command == "" || hookRunner == nil {
+		return
+	}
+	if err := hookRunner.RunHook(hook, command, config.WatchDir, result); err != nil {
+		ui.PrintWarning(fmt.Sprintf("执行钩子 %s 失败: %v", hook, err))
+	}
+}
+
+// current 返回当前的配置和钩子执行器，供执行器的回调函数在其他 goroutine 中读取
+func (s *WatchService) current() (*entity.WatchConfig, service.HookRunner) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config, s.hookRunner
+}
+
+// running 返回监控是否正在运行
+func (s *WatchService) running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isRunning
+}
+
+// executors 返回所有已创建的执行器
+func (s *WatchService) executors() []service.CommandExecutor {
+	var executors []service.CommandExecutor
+	if s.commandExecutor != nil {
+		executors = append(executors, s.commandExecutor)
+	}
+	return append(executors, s.ruleExecutors...)
+}
+
+// scheduleEvent 将事件加入当前批次，并重新开始静默期计时
+func (s *WatchService) scheduleEvent(event *entity.FileEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pendingEvents = append(s.pendingEvents, event)
+
+	debounce := s.config.Debounce
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
+	if s.debounceTimer == nil {
+		s.debounceTimer = time.AfterFunc(debounce, s.flushEvents)
+	} else {
+		s.debounceTimer.Reset(debounce)
+	}
+}
+
+// flushEvents 在静默期结束后按规则路由整批事件，每个命令最多执行一次
+func (s *WatchService) flushEvents() {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	s.mu.Lock()
+	events := s.pendingEvents
+	s.pendingEvents = nil
+	running := s.isRunning
+	s.mu.Unlock()
+
+	// 计时器可能在批次已被处理后再次触发
+	if len(events) == 0 || !running {
+		return
+	}
+
+	ui.PrintInfo(fmt.Sprintf("检测到 %d 个文件变更", len(events)))
+
+	ruleEvents, mainEvents := s.routeEvents(events)
+	for i, matched := range ruleEvents {
+		if len(matched) == 0 {
+			continue
+		}
+		rule := &s.config.Rules[i]
+		ui.PrintInfo(fmt.Sprintf("规则 %s 匹配 %d 个文件变更", rule.DisplayName(), len(matched)))
+		if err := s.ruleExecutors[i].Execute(rule.Command, s.config.WatchDir, matched); err != nil {
+			ui.PrintError(fmt.Sprintf("执行规则 %s 的命令失败: %v", rule.DisplayName(), err))
+		}
+	}
+
+	if len(mainEvents) > 0 && s.commandExecutor != nil {
+		if err := s.commandExecutor.Execute(s.config.Command, s.config.WatchDir, mainEvents); err != nil {
+			ui.PrintError(fmt.Sprintf("执行命令失败: %v", err))
+		}
+	}
+}
+
+// routeEvents 将事件分配给匹配的规则，未匹配任何规则的事件交给主命令
+func (s *WatchService) routeEvents(events []*entity.FileEvent) ([][]*entity.FileEvent, []*entity.FileEvent) {
+	ruleEvents := make([][]*entity.FileEvent, len(s.config.Rules))
+	var mainEvents []*entity.FileEvent
+
+	for _, event := range events {
+		rel := s.config.RelPath(event.Path)
+		matched := false
+		for i := range s.config.Rules {
+			if s.config.Rules[i].Matches(rel) {
+				ruleEvents[i] = append(ruleEvents[i], event)
+				matched = true
+			}
+		}
+		if !matched {
+			mainEvents = append(mainEvents, event)
+		}
+	}
+	return ruleEvents, mainEvents
 }
@@ -0,0 +1,129 @@
+package application
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/domain/service"
+)
+
+// fakeWatcher 记录注册的事件处理器，由测试直接发送事件
+type fakeWatcher struct {
+	handler func(event *entity.FileEvent) error
+}
+
+func (w *fakeWatcher) Start() error { return nil }
+
+func (w *fakeWatcher) Stop() error { return nil }
+
+func (w *fakeWatcher) OnFileEvent(handler func(event *entity.FileEvent) error) {
+	w.handler = handler
+}
+
+func (w *fakeWatcher) UpdateConfig(config *entity.WatchConfig) error { return nil }
+
+// fakeExecutor 将每次 Execute 收到的事件发送到 calls
+type fakeExecutor struct {
+	calls chan []*entity.FileEvent
+}
+
+func (e *fakeExecutor) Execute(command string, workDir string, events []*entity.FileEvent) error {
+	e.calls <- events
+	return nil
+}
+
+func (e *fakeExecutor) Terminate() error { return nil }
+
+func (e *fakeExecutor) OnRunComplete(handler func(result *entity.RunResult)) {}
+
+func (e *fakeExecutor) OnReady(handler func(result *entity.RunResult)) {}
+
+// nextCall 等待下一次 Execute 调用，超时时测试失败
+func (e *fakeExecutor) nextCall(t *testing.T, timeout time.Duration) []*entity.FileEvent {
+	t.Helper()
+	select {
+	case events := <-e.calls:
+		return events
+	case <-time.After(timeout):
+		t.Fatal("Execute() was not called")
+		return nil
+	}
+}
+
+// expectNoCall 在 wait 内没有新的 Execute 调用
+func (e *fakeExecutor) expectNoCall(t *testing.T, wait time.Duration) {
+	t.Helper()
+	select {
+	case events := <-e.calls:
+		t.Fatalf("Execute() called again with %d events, want no call", len(events))
+	case <-time.After(wait):
+	}
+}
+
+// TestWatchServiceDebounce 静默期内的一批事件只触发一次执行，并在最后一次事件之后携带整批事件
+func TestWatchServiceDebounce(t *testing.T) {
+	const debounce = 100 * time.Millisecond
+	watchDir := t.TempDir()
+	config := &entity.WatchConfig{
+		WatchDir: watchDir,
+		Command:  "go test ./...",
+		Debounce: debounce,
+	}
+	watcher := &fakeWatcher{}
+	executor := &fakeExecutor{calls: make(chan []*entity.FileEvent, 10)}
+	newExecutor := func(config *entity.WatchConfig, rule *entity.Rule) (service.CommandExecutor, error) {
+		return executor, nil
+	}
+
+	watchService := NewWatchService(config, watcher, newExecutor, nil, nil)
+	if err := watchService.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer watchService.Stop()
+
+	// 初始执行没有文件事件
+	if events := executor.nextCall(t, time.Second); events != nil {
+		t.Fatalf("initial Execute() events = %d, want nil", len(events))
+	}
+
+	// 每个事件都在上一个事件的静默期内到达
+	batch := []*entity.FileEvent{
+		entity.NewFileEvent(filepath.Join(watchDir, "a.go"), entity.EventWrite),
+		entity.NewFileEvent(filepath.Join(watchDir, "b.go"), entity.EventCreate),
+		entity.NewFileEvent(filepath.Join(watchDir, "c.go"), entity.EventRemove),
+		entity.NewFileEvent(filepath.Join(watchDir, "a.go"), entity.EventWrite),
+	}
+	var last time.Time
+	for i, event := range batch {
+		if i > 0 {
+			time.Sleep(debounce / 4)
+		}
+		last = time.Now()
+		watcher.handler(event)
+	}
+
+	events := executor.nextCall(t, time.Second)
+	if elapsed := time.Since(last); elapsed < debounce {
+		t.Errorf("Execute() called %v after the last event, want at least %v", elapsed, debounce)
+	}
+	if len(events) != len(batch) {
+		t.Fatalf("Execute() events = %d, want %d", len(events), len(batch))
+	}
+	for i := range batch {
+		if events[i] != batch[i] {
+			t.Errorf("Execute() events[%d] = %+v, want %+v", i, events[i], batch[i])
+		}
+	}
+	executor.expectNoCall(t, 3*debounce)
+
+	// 静默期结束后的事件开始新的批次
+	event := entity.NewFileEvent(filepath.Join(watchDir, "d.go"), entity.EventWrite)
+	watcher.handler(event)
+	events = executor.nextCall(t, time.Second)
+	if len(events) != 1 || events[0] != event {
+		t.Errorf("second Execute() events = %v, want [%+v]", events, event)
+	}
+	executor.expectNoCall(t, 3*debounce)
+}
@@ -0,0 +1,170 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FilesStdinMode 表示通过标准输入向命令传递变更文件列表的方式
+type FilesStdinMode string
+
+const (
+	// FilesStdinNone 不通过标准输入传递文件列表
+	FilesStdinNone FilesStdinMode = ""
+	// FilesStdinNewline 每行一个文件路径
+	FilesStdinNewline FilesStdinMode = "newline"
+	// FilesStdinNul 文件路径以 NUL 字符分隔，适合配合 xargs -0 使用
+	FilesStdinNul FilesStdinMode = "nul"
+)
+
+// BusyPolicy 表示文件变化时上一次命令仍在运行的处理策略
+type BusyPolicy string
+
+const (
+	// BusyDefault 未设置，主命令使用 BusyRestart，规则继承全局配置
+	BusyDefault BusyPolicy = ""
+	// BusyRestart 终止正在运行的命令并重新执行
+	BusyRestart BusyPolicy = "restart"
+	// BusyQueue 等待正在运行的命令结束后，使用期间累积的变更再执行一次
+	BusyQueue BusyPolicy = "queue"
+	// BusyIgnore 忽略命令运行期间的变更
+	BusyIgnore BusyPolicy = "ignore"
+)
+
+// supportedSignals 可在配置中使用的信号名称
+var supportedSignals = []string{"SIGTERM", "SIGINT", "SIGHUP", "SIGQUIT", "SIGKILL", "SIGUSR1", "SIGUSR2"}
+
+// NormalizeSignal 将 "term"、"SIGTERM" 等写法统一为 "SIGTERM"，不支持的信号返回错误
+func NormalizeSignal(name string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(normalized, "SIG") {
+		normalized = "SIG" + normalized
+	}
+	for _, signal := range supportedSignals {
+		if normalized == signal {
+			return normalized, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的信号 %q，可选值: %s", name, strings.Join(supportedSignals, "、"))
+}
+
+// CommandOptions 表示命令执行相关的选项
+// 零值表示使用默认值，规则中的零值表示继承全局配置；开关类选项为 nil 表示未设置，设置为 false 时可以关闭继承的开关
+type CommandOptions struct {
+	// 通过标准输入传递变更文件列表的方式
+	FilesStdin FilesStdinMode
+	// 上一次命令仍在运行时的处理策略
+	OnBusy BusyPolicy
+	// 终止命令时向进程组发送的信号，默认为 SIGTERM
+	KillSignal string
+	// 命令仍在运行时，文件变化后向进程组发送该信号让其重新加载，而不是重启命令
+	ReloadSignal string
+	// 发送终止信号后等待进程退出的宽限期，超时后强制结束进程组
+	GracePeriod time.Duration
+	// 命令的最长执行时间，超时后终止进程组，为 0 表示不限制
+	Timeout time.Duration
+	// 是否监管长时间运行的命令，命令意外退出时按指数退避自动重启
+	Supervise *bool
+	// 监管模式下连续崩溃的最大重启次数，超过后停止自动重启
+	MaxRestarts int
+	// 监管模式下第一次自动重启前的等待时间，之后每次加倍
+	RestartDelay time.Duration
+	// 是否在伪终端中运行命令，使检测终端的工具保留颜色等输出格式
+	PTY *bool
+	// 命令启动后判断其是否就绪的检查，为 nil 表示不检查
+	Ready *ReadyProbe
+	// 命令输出的前缀、日志文件和保留行数
+	Output OutputOptions
+}
+
+// Supervised 返回是否监管命令
+func (o CommandOptions) Supervised() bool {
+	return o.Supervise != nil && *o.Supervise
+}
+
+// UsePTY 返回是否在伪终端中运行命令
+func (o CommandOptions) UsePTY() bool {
+	return o.PTY != nil && *o.PTY
+}
+
+// Merge 用 override 中的非零值和已设置的开关覆盖当前选项
+func (o CommandOptions) Merge(override CommandOptions) CommandOptions {
+	if override.FilesStdin != FilesStdinNone {
+		o.FilesStdin = override.FilesStdin
+	}
+	if override.OnBusy != BusyDefault {
+		o.OnBusy = override.OnBusy
+	}
+	if override.KillSignal != "" {
+		o.KillSignal = override.KillSignal
+	}
+	if override.ReloadSignal != "" {
+		o.ReloadSignal = override.ReloadSignal
+	}
+	if override.GracePeriod > 0 {
+		o.GracePeriod = override.GracePeriod
+	}
+	if override.Timeout > 0 {
+		o.Timeout = override.Timeout
+	}
+	if override.Supervise != nil {
+		o.Supervise = override.Supervise
+	}
+	if override.MaxRestarts > 0 {
+		o.MaxRestarts = override.MaxRestarts
+	}
+	if override.RestartDelay > 0 {
+		o.RestartDelay = override.RestartDelay
+	}
+	if override.PTY != nil {
+		o.PTY = override.PTY
+	}
+	if override.Ready != nil {
+		o.Ready = override.Ready
+	}
+	o.Output = o.Output.Merge(override.Output)
+	return o
+}
+
+// Validate 校验选项
+func (o CommandOptions) Validate() error {
+	switch o.FilesStdin {
+	case FilesStdinNone, FilesStdinNewline, FilesStdinNul:
+	default:
+		return fmt.Errorf("无效的 changed_files_stdin %q，可选值: newline、nul", o.FilesStdin)
+	}
+	switch o.OnBusy {
+	case BusyDefault, BusyRestart, BusyQueue, BusyIgnore:
+	default:
+		return fmt.Errorf("无效的 on_busy %q，可选值: restart、queue、ignore", o.OnBusy)
+	}
+	if o.KillSignal != "" {
+		if _, err := NormalizeSignal(o.KillSignal); err != nil {
+			return fmt.Errorf("无效的 kill_signal: %w", err)
+		}
+	}
+	if o.ReloadSignal != "" {
+		if _, err := NormalizeSignal(o.ReloadSignal); err != nil {
+			return fmt.Errorf("无效的 reload_signal: %w", err)
+		}
+	}
+	if o.GracePeriod < 0 {
+		return fmt.Errorf("grace_period_ms 不能为负数")
+	}
+	if o.Timeout < 0 {
+		return fmt.Errorf("timeout_ms 不能为负数")
+	}
+	if o.MaxRestarts < 0 {
+		return fmt.Errorf("max_restarts 不能为负数")
+	}
+	if o.RestartDelay < 0 {
+		return fmt.Errorf("restart_delay_ms 不能为负数")
+	}
+	if o.Ready != nil {
+		if err := o.Ready.validate(); err != nil {
+			return err
+		}
+	}
+	return o.Output.validate()
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+// boolPtr 返回指向 v 的指针
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+// TestCommandOptionsMergeInherit 零值和未设置的开关沿用原选项
+func TestCommandOptionsMergeInherit(t *testing.T) {
+	base := CommandOptions{
+		OnBusy:      BusyQueue,
+		KillSignal:  "SIGINT",
+		Timeout:     time.Minute,
+		Supervise:   boolPtr(true),
+		MaxRestarts: 3,
+		PTY:         boolPtr(true),
+		Ready:       &ReadyProbe{TCP: "localhost:8080"},
+		Output:      OutputOptions{Prefix: boolPtr(true), Lines: 50},
+	}
+
+	merged := base.Merge(CommandOptions{})
+	if merged.OnBusy != BusyQueue || merged.KillSignal != "SIGINT" || merged.Timeout != time.Minute || merged.MaxRestarts != 3 {
+		t.Errorf("Merge(zero) = %+v, want base values", merged)
+	}
+	if !merged.Supervised() || !merged.UsePTY() || !merged.Output.HasPrefix() || merged.Output.Lines != 50 {
+		t.Errorf("Merge(zero) switches = supervise %v, pty %v, prefix %v, lines %d, want inherited",
+			merged.Supervised(), merged.UsePTY(), merged.Output.HasPrefix(), merged.Output.Lines)
+	}
+	if merged.Ready != base.Ready {
+		t.Errorf("Merge(zero).Ready = %v, want %v", merged.Ready, base.Ready)
+	}
+}
+
+// TestCommandOptionsMergeOverride 非零值和已设置的开关覆盖原选项，包括设置为 false 的开关
+func TestCommandOptionsMergeOverride(t *testing.T) {
+	base := CommandOptions{
+		FilesStdin: FilesStdinNewline,
+		OnBusy:     BusyQueue,
+		Supervise:  boolPtr(true),
+		PTY:        boolPtr(true),
+		Output:     OutputOptions{Prefix: boolPtr(true), Timestamps: boolPtr(true)},
+	}
+	ready := &ReadyProbe{HTTP: "http://localhost:8080/health"}
+	override := CommandOptions{
+		FilesStdin:   FilesStdinNul,
+		OnBusy:       BusyRestart,
+		ReloadSignal: "SIGHUP",
+		GracePeriod:  time.Second,
+		Supervise:    boolPtr(false),
+		RestartDelay: 2 * time.Second,
+		PTY:          boolPtr(false),
+		Ready:        ready,
+		Output:       OutputOptions{Timestamps: boolPtr(false), LogFile: boolPtr(true)},
+	}
+
+	merged := base.Merge(override)
+	if merged.FilesStdin != FilesStdinNul || merged.OnBusy != BusyRestart || merged.ReloadSignal != "SIGHUP" {
+		t.Errorf("Merge() = %+v, want overridden values", merged)
+	}
+	if merged.GracePeriod != time.Second || merged.RestartDelay != 2*time.Second || merged.Ready != ready {
+		t.Errorf("Merge() durations and ready = %v, %v, %v", merged.GracePeriod, merged.RestartDelay, merged.Ready)
+	}
+	if merged.Supervised() || merged.UsePTY() {
+		t.Errorf("Merge() supervise = %v, pty = %v, want false switches to win", merged.Supervised(), merged.UsePTY())
+	}
+	if !merged.Output.HasPrefix() || merged.Output.HasTimestamps() || !merged.Output.HasLogFile() {
+		t.Errorf("Merge() output = prefix %v, timestamps %v, log file %v, want true, false, true",
+			merged.Output.HasPrefix(), merged.Output.HasTimestamps(), merged.Output.HasLogFile())
+	}
+
+	// 合并不修改原选项
+	if !base.Supervised() || base.OnBusy != BusyQueue || !base.Output.HasTimestamps() {
+		t.Errorf("Merge() modified the receiver: %+v", base)
+	}
+}
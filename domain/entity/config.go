@@ -4,8 +4,31 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
+// WatcherBackend 表示文件监控的实现方式
+type WatcherBackend string
+
+const (
+	// BackendDefault 未设置，按 BackendAuto 处理，用户配置中的后端可以作为默认值
+	BackendDefault WatcherBackend = ""
+	// BackendAuto 优先使用 fsnotify，不可用时回退到轮询
+	BackendAuto WatcherBackend = "auto"
+	// BackendFSNotify 使用操作系统的文件通知机制
+	BackendFSNotify WatcherBackend = "fsnotify"
+	// BackendPoll 定期扫描文件的修改时间和大小
+	BackendPoll WatcherBackend = "poll"
+)
+
+// DefaultStdinEscape 默认的标准输入转义前缀，以其开头的输入行由 watchs 处理而不转发给命令
+const DefaultStdinEscape = "~"
+
+// StateDirName 是监控目录下保存 watchs 运行数据（如执行历史）的目录，始终不被监控
+const StateDirName = ".watchs"
+
 // WatchConfig 表示文件监控的配置实体
 type WatchConfig struct {
 	// 要监控的目录
@@ -14,11 +37,51 @@ type WatchConfig struct {
 	FileTypes []string
 	// 要排除的目录或文件
 	ExcludePaths []string
+	// ExcludePaths 中相对路径的基准目录，为配置文件所在的目录，为空时使用当前目录
+	BaseDir string
+	// 要监控的文件的 glob 模式，支持 ** 和以 ! 开头的取反模式
+	Include []string
+	// 要排除的文件的 glob 模式，支持 ** 和以 ! 开头的取反模式
+	Exclude []string
 	// 文件变化时要执行的命令
 	Command string
+	// 任务流水线，设置后代替 Command 按依赖关系执行
+	Tasks []Task
+	// 按文件模式路由命令的规则，匹配规则的变化执行规则的命令，其余变化执行 Command
+	Rules []Rule
+	// 命令执行选项，规则可以覆盖
+	CommandOptions
+	// 生命周期钩子命令
+	Hooks Hooks
+	// 是否将 watchs 的标准输入转发给正在运行的主命令
+	ForwardStdin bool
+	// 标准输入的转义前缀，为空时使用 DefaultStdinEscape
+	StdinEscape string
+	// 是否遵循监控目录中的 .gitignore 文件（.watchsignore 始终生效）
+	UseGitignore bool
+	// 文件监控后端
+	Backend WatcherBackend
+	// 轮询后端的扫描间隔
+	PollInterval time.Duration
+	// 防抖静默期，一批变更在此时间内没有新事件后才执行命令
+	Debounce time.Duration
+	// 是否输出彩色信息，为 nil 时输出彩色信息
+	Color *bool
+	// 命名的配置方案，通过 --profile 或 WATCHS_PROFILE 选择后覆盖以上配置项
+	Profiles map[string]Profile
+	// 正在监控并自动重新加载的配置文件的绝对路径，其变化只触发重新加载，不执行命令
+	ConfigFile string
 }
 
-// NewWatchConfig 创建一个新的监控配置
+// ResolvePath 将相对路径解析为相对于 baseDir 的路径，baseDir 为空或路径为绝对路径时原样返回
+func ResolvePath(baseDir, path string) string {
+	if baseDir == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// NewWatchConfig 创建一个新的监控配置，只校验监控目录，其余配置项由 Validate 校验
 func NewWatchConfig(watchDir string, fileTypes []string, excludePaths []string, command string) (*WatchConfig, error) {
 	if watchDir == "" {
 		return nil, fmt.Errorf("监控目录不能为空")
@@ -35,46 +98,261 @@ func NewWatchConfig(watchDir string, fileTypes []string, excludePaths []string,
 		return nil, fmt.Errorf("监控目录不存在: %s", absPath)
 	}
 
-	if command == "" {
-		return nil, fmt.Errorf("执行命令不能为空")
-	}
-
 	return &WatchConfig{
 		WatchDir:     absPath,
 		FileTypes:    fileTypes,
 		ExcludePaths: excludePaths,
 		Command:      command,
+		UseGitignore: true,
 	}, nil
 }
 
-// ShouldWatch 判断给定文件是否应该被监控
-func (c *WatchConfig) ShouldWatch(path string) bool {
-	// 检查是否在排除列表中
-	for _, excludePath := range c.ExcludePaths {
-		absExcludePath, err := filepath.Abs(excludePath)
-		if err == nil && (path == absExcludePath || filepath.HasPrefix(path, absExcludePath+string(os.PathSeparator))) {
-			return false
+// Validate 校验配置项，创建或修改配置后都应调用
+func (c *WatchConfig) Validate() error {
+	if c.Command == "" && len(c.Tasks) == 0 && len(c.Rules) == 0 {
+		return fmt.Errorf("执行命令不能为空")
+	}
+	if _, err := SortTasks(c.Tasks); err != nil {
+		return err
+	}
+	if len(c.Tasks) > 0 && c.Supervised() {
+		return fmt.Errorf("supervise 不能与 tasks 同时使用，任务流水线不支持自动重启")
+	}
+	if len(c.Tasks) > 0 && c.Ready != nil {
+		return fmt.Errorf("ready 不能与 tasks 同时使用，任务流水线不支持就绪检查")
+	}
+	if len(c.Tasks) > 0 && c.ReloadSignal != "" {
+		return fmt.Errorf("reload_signal 不能与 tasks 同时使用，任务流水线不支持发送重新加载信号")
+	}
+	if err := c.CommandOptions.Validate(); err != nil {
+		return err
+	}
+	for i := range c.Rules {
+		if err := c.Rules[i].validate(); err != nil {
+			return err
+		}
+	}
+	if c.ForwardStdin {
+		if c.Command == "" || len(c.Tasks) > 0 {
+			return fmt.Errorf("forward_stdin 需要设置 command，输入只转发给主命令，不支持任务流水线")
+		}
+		if c.FilesStdin != FilesStdinNone {
+			return fmt.Errorf("forward_stdin 不能与 changed_files_stdin 同时使用")
+		}
+	}
+
+	for _, pattern := range append(append([]string{}, c.Include...), c.Exclude...) {
+		if err := validatePattern(pattern); err != nil {
+			return fmt.Errorf("无效的匹配模式 %q: %w", pattern, err)
 		}
+	}
+
+	if err := ValidateBackend(c.Backend); err != nil {
+		return err
+	}
 
-		// 支持通配符匹配
-		matched, err := filepath.Match(excludePath, filepath.Base(path))
-		if err == nil && matched {
-			return false
+	for _, name := range c.ProfileNames() {
+		profile := c.Profiles[name]
+		if err := profile.validate(); err != nil {
+			return fmt.Errorf("配置方案 %s: %w", name, err)
+		}
+		if profile.Backend != "" {
+			if err := ValidateBackend(profile.Backend); err != nil {
+				return fmt.Errorf("配置方案 %s: %w", name, err)
+			}
 		}
 	}
+	return nil
+}
+
+// ValidateBackend 校验监控后端
+func ValidateBackend(backend WatcherBackend) error {
+	switch backend {
+	case BackendDefault, BackendAuto, BackendFSNotify, BackendPoll:
+		return nil
+	default:
+		return fmt.Errorf("无效的监控后端 %q，可选值: auto、fsnotify、poll", backend)
+	}
+}
+
+// ColorEnabled 返回是否输出彩色信息，未设置时输出彩色信息
+func (c *WatchConfig) ColorEnabled() bool {
+	return c.Color == nil || *c.Color
+}
+
+// ProfileNames 按名称排序返回所有配置方案的名称
+func (c *WatchConfig) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
 
-	// 如果没有指定文件类型，监控所有文件
-	if len(c.FileTypes) == 0 {
+// ShouldWatch 判断给定文件是否应该被监控
+// 规则的匹配模式视为额外的 include，匹配规则的文件不受 file_types 和 include 的限制，但仍会被排除
+func (c *WatchConfig) ShouldWatch(path string) bool {
+	if c.isStateDir(path) || c.isExcludedPath(path) || (c.ConfigFile != "" && path == c.ConfigFile) {
+		return false
+	}
+
+	rel := c.RelPath(path)
+
+	// 检查排除模式
+	if matchPatterns(c.Exclude, rel) {
+		return false
+	}
+
+	// file_types 是 include 的简写，两者都为空时监控所有文件
+	if len(c.FileTypes) == 0 && len(c.Include) == 0 {
 		return true
 	}
 
-	// 检查文件类型是否匹配
-	ext := filepath.Ext(path)
-	for _, fileType := range c.FileTypes {
-		if ext == fileType {
+	if matchPatterns(c.includePatterns(), rel) {
+		return true
+	}
+	for i := range c.Rules {
+		if c.Rules[i].Matches(rel) {
 			return true
 		}
 	}
+	return false
+}
 
+// Escape 返回标准输入的转义前缀
+func (c *WatchConfig) Escape() string {
+	if c.StdinEscape == "" {
+		return DefaultStdinEscape
+	}
+	return c.StdinEscape
+}
+
+// HasMainCommand 判断是否配置了不属于任何规则的主命令或任务流水线
+func (c *WatchConfig) HasMainCommand() bool {
+	return c.Command != "" || len(c.Tasks) > 0
+}
+
+// OptionsFor 返回主命令（rule 为 nil）或规则生效的命令执行选项
+func (c *WatchConfig) OptionsFor(rule *Rule) CommandOptions {
+	if rule == nil {
+		return c.CommandOptions
+	}
+	return c.CommandOptions.Merge(rule.CommandOptions)
+}
+
+// ShouldSkipDir 判断目录是否应该整体跳过，不注册监控
+func (c *WatchConfig) ShouldSkipDir(path string) bool {
+	if path == c.WatchDir {
+		return false
+	}
+	return c.isStateDir(path) || c.isExcludedPath(path) || matchPatterns(c.Exclude, c.RelPath(path))
+}
+
+// StateDir 返回保存 watchs 运行数据的目录
+func (c *WatchConfig) StateDir() string {
+	return filepath.Join(c.WatchDir, StateDirName)
+}
+
+// isStateDir 检查路径是否位于 watchs 运行数据目录中
+func (c *WatchConfig) isStateDir(path string) bool {
+	stateDir := c.StateDir()
+	return path == stateDir || strings.HasPrefix(path, stateDir+string(os.PathSeparator))
+}
+
+// isExcludedPath 检查路径是否在 ExcludePaths 排除列表中
+func (c *WatchConfig) isExcludedPath(path string) bool {
+	for _, excludePath := range c.ExcludePaths {
+		if matchExcludePath(c.BaseDir, excludePath, path) {
+			return true
+		}
+	}
 	return false
 }
+
+// matchExcludePath 检查路径是否匹配 ExcludePaths 中的一项，相对路径基于 baseDir
+func matchExcludePath(baseDir, excludePath, path string) bool {
+	absExcludePath, err := filepath.Abs(ResolvePath(baseDir, excludePath))
+	if err == nil && (path == absExcludePath || filepath.HasPrefix(path, absExcludePath+string(os.PathSeparator))) {
+		return true
+	}
+
+	// 支持通配符匹配
+	matched, err := filepath.Match(excludePath, filepath.Base(path))
+	return err == nil && matched
+}
+
+// UnusedExcludes 遍历监控目录，返回没有匹配任何文件或目录的 ExcludePaths 和 Exclude 项的下标
+// 以 ! 开头的取反模式按去掉 ! 后的模式检查
+func (c *WatchConfig) UnusedExcludes() ([]int, []int, error) {
+	usedPaths := make([]bool, len(c.ExcludePaths))
+	usedPatterns := make([]bool, len(c.Exclude))
+
+	err := filepath.Walk(c.WatchDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// 无法访问的文件不影响其他路径的检查
+			if path != c.WatchDir {
+				return nil
+			}
+			return err
+		}
+		if path == c.WatchDir {
+			return nil
+		}
+		if c.isStateDir(path) {
+			return filepath.SkipDir
+		}
+
+		rel := c.RelPath(path)
+		for i, excludePath := range c.ExcludePaths {
+			if !usedPaths[i] && matchExcludePath(c.BaseDir, excludePath, path) {
+				usedPaths[i] = true
+			}
+		}
+		for i, pattern := range c.Exclude {
+			if !usedPatterns[i] && matchPattern(strings.TrimPrefix(pattern, "!"), rel) {
+				usedPatterns[i] = true
+			}
+		}
+
+		// 所有排除项都已匹配时不再继续遍历
+		if len(unusedIndexes(usedPaths)) == 0 && len(unusedIndexes(usedPatterns)) == 0 {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return unusedIndexes(usedPaths), unusedIndexes(usedPatterns), nil
+}
+
+// unusedIndexes 返回值为 false 的下标
+func unusedIndexes(used []bool) []int {
+	var indexes []int
+	for i, ok := range used {
+		if !ok {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// includePatterns 返回 file_types 展开后与 include 合并的模式列表
+func (c *WatchConfig) includePatterns() []string {
+	patterns := make([]string, 0, len(c.FileTypes)+len(c.Include))
+	for _, fileType := range c.FileTypes {
+		patterns = append(patterns, "*"+fileType)
+	}
+	return append(patterns, c.Include...)
+}
+
+// RelPath 返回相对于监控目录、以 / 分隔的路径
+func (c *WatchConfig) RelPath(path string) string {
+	rel, err := filepath.Rel(c.WatchDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return filepath.Base(path)
+	}
+	return filepath.ToSlash(rel)
+}
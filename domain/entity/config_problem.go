@@ -0,0 +1,20 @@
+package entity
+
+// ConfigProblem 表示配置文件中的一个问题
+type ConfigProblem struct {
+	// 问题所在配置项的路径，如 rules[0].command，为空表示整个配置文件
+	Path string
+	// 问题在文件中的行号和列号，从 1 开始，为 0 表示未知
+	Line   int
+	Column int
+	// 问题描述
+	Message string
+}
+
+// String 以 "路径: 描述" 的格式返回问题，路径为空时只返回描述
+func (p ConfigProblem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return p.Path + ": " + p.Message
+}
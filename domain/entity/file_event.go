@@ -18,6 +18,24 @@ const (
 	EventChmod
 )
 
+// String 返回事件类型的名称
+func (t EventType) String() string {
+	switch t {
+	case EventCreate:
+		return "create"
+	case EventWrite:
+		return "write"
+	case EventRemove:
+		return "remove"
+	case EventRename:
+		return "rename"
+	case EventChmod:
+		return "chmod"
+	default:
+		return "unknown"
+	}
+}
+
 // FileEvent 表示文件变更事件
 type FileEvent struct {
 	// 文件路径
@@ -36,3 +54,16 @@ func NewFileEvent(path string, eventType EventType) *FileEvent {
 		Timestamp: time.Now(),
 	}
 }
+
+// ChangedFiles 返回事件批次中去重后的文件路径，保持事件顺序
+func ChangedFiles(events []*FileEvent) []string {
+	var files []string
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		if !seen[event.Path] {
+			seen[event.Path] = true
+			files = append(files, event.Path)
+		}
+	}
+	return files
+}
@@ -0,0 +1,64 @@
+package entity
+
+// HookType 表示生命周期钩子的触发时机
+type HookType string
+
+const (
+	// HookOnStart 开始监控后、执行初始命令前触发
+	HookOnStart HookType = "on_start"
+	// HookOnReady 命令通过就绪检查后触发
+	HookOnReady HookType = "on_ready"
+	// HookOnSuccess 命令执行成功后触发
+	HookOnSuccess HookType = "on_success"
+	// HookOnFailure 命令执行失败或超时后触发
+	HookOnFailure HookType = "on_failure"
+	// HookOnStop 停止监控、终止命令后触发
+	HookOnStop HookType = "on_stop"
+)
+
+// Hooks 表示生命周期钩子命令，为空的钩子不执行
+type Hooks struct {
+	OnStart   string
+	OnReady   string
+	OnSuccess string
+	OnFailure string
+	OnStop    string
+}
+
+// Merge 用 override 中不为空的钩子覆盖当前钩子
+func (h Hooks) Merge(override Hooks) Hooks {
+	if override.OnStart != "" {
+		h.OnStart = override.OnStart
+	}
+	if override.OnReady != "" {
+		h.OnReady = override.OnReady
+	}
+	if override.OnSuccess != "" {
+		h.OnSuccess = override.OnSuccess
+	}
+	if override.OnFailure != "" {
+		h.OnFailure = override.OnFailure
+	}
+	if override.OnStop != "" {
+		h.OnStop = override.OnStop
+	}
+	return h
+}
+
+// Command 返回指定钩子的命令
+func (h Hooks) Command(hook HookType) string {
+	switch hook {
+	case HookOnStart:
+		return h.OnStart
+	case HookOnReady:
+		return h.OnReady
+	case HookOnSuccess:
+		return h.OnSuccess
+	case HookOnFailure:
+		return h.OnFailure
+	case HookOnStop:
+		return h.OnStop
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,56 @@
+package entity
+
+import "fmt"
+
+// OutputOptions 表示命令输出的处理方式
+// 零值表示原样输出，规则中的零值表示继承全局配置；开关为 nil 表示未设置
+type OutputOptions struct {
+	// 是否在每行输出前添加彩色的命令名称（任务名或规则名）
+	Prefix *bool
+	// 是否在每行输出前添加时间
+	Timestamps *bool
+	// 是否同时将输出写入监控目录下 .watchs/logs 中以命令名称命名的日志文件，超过大小上限后轮转
+	LogFile *bool
+	// 执行结果中保留的最后几行输出，为 0 时使用默认值
+	Lines int
+}
+
+// HasPrefix 返回是否在每行输出前添加命令名称
+func (o OutputOptions) HasPrefix() bool {
+	return o.Prefix != nil && *o.Prefix
+}
+
+// HasTimestamps 返回是否在每行输出前添加时间
+func (o OutputOptions) HasTimestamps() bool {
+	return o.Timestamps != nil && *o.Timestamps
+}
+
+// HasLogFile 返回是否将输出写入日志文件
+func (o OutputOptions) HasLogFile() bool {
+	return o.LogFile != nil && *o.LogFile
+}
+
+// Merge 用 override 中的非零值和已设置的开关覆盖当前选项
+func (o OutputOptions) Merge(override OutputOptions) OutputOptions {
+	if override.Prefix != nil {
+		o.Prefix = override.Prefix
+	}
+	if override.Timestamps != nil {
+		o.Timestamps = override.Timestamps
+	}
+	if override.LogFile != nil {
+		o.LogFile = override.LogFile
+	}
+	if override.Lines > 0 {
+		o.Lines = override.Lines
+	}
+	return o
+}
+
+// validate 校验选项
+func (o OutputOptions) validate() error {
+	if o.Lines < 0 {
+		return fmt.Errorf("output.lines 不能为负数")
+	}
+	return nil
+}
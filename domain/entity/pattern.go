@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"path"
+	"strings"
+
+	"github.com/watchs/internal/glob"
+)
+
+// matchPatterns 按顺序匹配模式列表，最后一个匹配的模式决定结果
+// 以 ! 开头的模式表示取反；不含 / 的模式匹配任意层级的文件名
+func matchPatterns(patterns []string, rel string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		if negate {
+			pattern = pattern[1:]
+		}
+		if matchPattern(pattern, rel) {
+			matched = !negate
+		}
+	}
+	return matched
+}
+
+// matchPattern 匹配单个模式
+func matchPattern(pattern, rel string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		matched, err := path.Match(pattern, path.Base(rel))
+		return err == nil && matched
+	}
+	return glob.Match(pattern, rel)
+}
+
+// validatePattern 校验模式语法
+func validatePattern(pattern string) error {
+	return glob.ValidatePattern(strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "/"))
+}
+
+// ValidatePattern 校验 include、exclude 和规则中的匹配模式语法
+func ValidatePattern(pattern string) error {
+	return validatePattern(pattern)
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestMatchPatterns 覆盖 ** 匹配、文件名匹配和取反模式
+func TestMatchPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		rel      string
+		want     bool
+	}{
+		{"double star any depth", []string{"src/**/*.go"}, "src/a/b/main.go", true},
+		{"double star zero depth", []string{"src/**/*.go"}, "src/main.go", true},
+		{"double star other dir", []string{"src/**/*.go"}, "cmd/main.go", false},
+		{"trailing double star", []string{"vendor/**"}, "vendor/pkg/a.go", true},
+		{"trailing double star not dir itself", []string{"vendor/**"}, "vendor", false},
+		{"leading double star", []string{"**/testdata/*"}, "a/b/testdata/x.json", true},
+		{"base name any depth", []string{"*.d.ts"}, "web/types/index.d.ts", true},
+		{"base name no match", []string{"*.d.ts"}, "web/index.ts", false},
+		{"leading slash anchors to root", []string{"/gen/*.go"}, "gen/a.go", true},
+		{"leading slash nested", []string{"/gen/*.go"}, "pkg/gen/a.go", false},
+		{"single star one segment", []string{"src/*.go"}, "src/a/b.go", false},
+		{"negation excludes", []string{"**/*.go", "!**/*_test.go"}, "pkg/a_test.go", false},
+		{"negation keeps others", []string{"**/*.go", "!**/*_test.go"}, "pkg/a.go", true},
+		{"last match wins", []string{"*.go", "!gen/*.go", "gen/keep.go"}, "gen/keep.go", true},
+		{"negation only", []string{"!*.go"}, "a.go", false},
+		{"no patterns", nil, "a.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchPatterns(tt.patterns, tt.rel); got != tt.want {
+				t.Errorf("matchPatterns(%q, %q) = %v, want %v", tt.patterns, tt.rel, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestValidatePattern 取反前缀和开头的 / 不影响语法检查
+func TestValidatePattern(t *testing.T) {
+	for _, pattern := range []string{"src/**/*.go", "!**/*_test.go", "/gen/[a-z]*.go", "**"} {
+		if err := ValidatePattern(pattern); err != nil {
+			t.Errorf("ValidatePattern(%q) error = %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"src/[a", "![z", "/**/a[.go"} {
+		if err := ValidatePattern(pattern); err == nil {
+			t.Errorf("ValidatePattern(%q) error = nil, want syntax error", pattern)
+		}
+	}
+}
+
+// TestShouldWatchPatterns include 和 exclude 共同决定是否监控文件，exclude 优先
+func TestShouldWatchPatterns(t *testing.T) {
+	watchDir := filepath.Join(t.TempDir(), "project")
+	config := &WatchConfig{
+		WatchDir:  watchDir,
+		FileTypes: []string{".go"},
+		Include:   []string{"web/**/*.ts", "!web/**/*.d.ts"},
+		Exclude:   []string{"**/*_gen.go", "!keep_gen.go"},
+	}
+
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"main.go", true},
+		{"pkg/api/server.go", true},
+		{"pkg/api/server_gen.go", false},
+		{"keep_gen.go", true},
+		{"web/src/app.ts", true},
+		{"web/src/types.d.ts", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(watchDir, filepath.FromSlash(tt.rel))
+		if got := config.ShouldWatch(path); got != tt.want {
+			t.Errorf("ShouldWatch(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
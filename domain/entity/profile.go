@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Profile 表示配置中的一个命名配置方案，如 dev、test、ci
+// 零值表示沿用基础配置，开关设置为 false 时关闭基础配置中的开关；列表类配置项设置为空列表时清空基础配置中的列表
+type Profile struct {
+	// 要监控的目录
+	WatchDir string
+	// 要监控的文件类型
+	FileTypes []string
+	// 要排除的目录或文件
+	ExcludePaths []string
+	// 要监控的文件的 glob 模式
+	Include []string
+	// 要排除的文件的 glob 模式
+	Exclude []string
+	// 文件变化时要执行的命令，设置后代替基础配置中的任务流水线
+	Command string
+	// 任务流水线
+	Tasks []Task
+	// 路由规则
+	Rules []Rule
+	// 命令执行选项，与基础配置的选项合并
+	CommandOptions
+	// 生命周期钩子命令，与基础配置的钩子合并
+	Hooks Hooks
+	// 是否将 watchs 的标准输入转发给正在运行的主命令，为 nil 时沿用基础配置
+	ForwardStdin *bool
+	// 标准输入的转义前缀
+	StdinEscape string
+	// 是否遵循监控目录中的 .gitignore 文件，为 nil 时沿用基础配置
+	UseGitignore *bool
+	// 文件监控后端
+	Backend WatcherBackend
+	// 轮询后端的扫描间隔
+	PollInterval time.Duration
+	// 防抖静默期
+	Debounce time.Duration
+	// 是否输出彩色信息，为 nil 时沿用基础配置
+	Color *bool
+}
+
+// validate 校验配置方案中不依赖基础配置的配置项，合并后的配置仍需调用 WatchConfig.Validate
+func (p *Profile) validate() error {
+	if err := p.CommandOptions.Validate(); err != nil {
+		return err
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].validate(); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range append(append([]string{}, p.Include...), p.Exclude...) {
+		if err := validatePattern(pattern); err != nil {
+			return fmt.Errorf("无效的匹配模式 %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ReadyProbe 表示判断长时间运行的命令（如开发服务器）是否已就绪的检查
+// 配置的所有检查都通过后命令才视为就绪
+type ReadyProbe struct {
+	// HTTP GET 返回 2xx 状态码的地址，如 http://localhost:8080/health
+	HTTP string
+	// 可以建立连接的 TCP 地址，如 localhost:8080
+	TCP string
+	// 命令输出中需要出现的行所匹配的正则表达式
+	LogPattern string
+	// 等待就绪的最长时间
+	Timeout time.Duration
+	// HTTP 和 TCP 检查的间隔
+	Interval time.Duration
+}
+
+// validate 校验就绪检查
+func (p *ReadyProbe) validate() error {
+	if p.HTTP == "" && p.TCP == "" && p.LogPattern == "" {
+		return fmt.Errorf("ready 至少需要配置 http、tcp 或 log 中的一项")
+	}
+	if p.HTTP != "" && !strings.HasPrefix(p.HTTP, "http://") && !strings.HasPrefix(p.HTTP, "https://") {
+		return fmt.Errorf("无效的 ready.http %q，应以 http:// 或 https:// 开头", p.HTTP)
+	}
+	if p.TCP != "" {
+		if _, _, err := net.SplitHostPort(p.TCP); err != nil {
+			return fmt.Errorf("无效的 ready.tcp %q: %w", p.TCP, err)
+		}
+	}
+	if p.LogPattern != "" {
+		if _, err := regexp.Compile(p.LogPattern); err != nil {
+			return fmt.Errorf("无效的 ready.log %q: %w", p.LogPattern, err)
+		}
+	}
+	if p.Timeout < 0 || p.Interval < 0 {
+		return fmt.Errorf("ready.timeout_ms 和 ready.interval_ms 不能为负数")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule 表示按文件模式路由命令的规则
+type Rule struct {
+	// 规则名称，用于显示，可为空
+	Name string
+	// 匹配的 glob 模式，语法与 Include 相同
+	Patterns []string
+	// 匹配的文件变化时执行的命令
+	Command string
+	// 覆盖全局配置的命令执行选项
+	CommandOptions
+}
+
+// DisplayName 返回用于显示的规则名称
+func (r *Rule) DisplayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return strings.Join(r.Patterns, ",")
+}
+
+// Matches 判断相对于监控目录的路径是否匹配该规则
+func (r *Rule) Matches(rel string) bool {
+	return matchPatterns(r.Patterns, rel)
+}
+
+// validate 校验规则
+func (r *Rule) validate() error {
+	if len(r.Patterns) == 0 {
+		return fmt.Errorf("规则 %s 的匹配模式不能为空", r.DisplayName())
+	}
+	for _, pattern := range r.Patterns {
+		if err := validatePattern(pattern); err != nil {
+			return fmt.Errorf("规则 %s 的匹配模式 %q 无效: %w", r.DisplayName(), pattern, err)
+		}
+	}
+	if r.Command == "" {
+		return fmt.Errorf("规则 %s 的命令不能为空", r.DisplayName())
+	}
+	if err := r.CommandOptions.Validate(); err != nil {
+		return fmt.Errorf("规则 %s: %w", r.DisplayName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// RunResult 记录一次命令执行的结果
+type RunResult struct {
+	// 执行ID，与传给子进程的 WATCHS_RUN_ID 一致
+	RunID string
+	// 实际执行的命令（已展开模板）
+	Command string
+	// 触发本次执行的文件事件，初始执行时为空，只在执行过程中可用
+	Events []*FileEvent
+	// 触发本次执行的文件路径，执行历史中只保存该字段
+	Files []string
+	// 开始执行的时间
+	StartTime time.Time
+	// 执行耗时
+	Duration time.Duration
+	// 进程退出码，被信号终止时为 -1
+	ExitCode int
+	// 终止进程的信号名称，如 SIGTERM，正常退出时为空
+	Signal string
+	// 是否由 watchs 主动终止（重启或停止监控）
+	Terminated bool
+	// 是否因超过 timeout 被终止
+	TimedOut bool
+	// 命令输出的末尾部分
+	Output string
+	// 退出码之外的失败原因，如等待进程出错或流水线中的任务失败
+	Err error
+}
+
+// NewRunResult 创建一次命令执行的结果记录，开始时间为当前时间
+func NewRunResult(runID string, command string, events []*FileEvent) *RunResult {
+	return &RunResult{
+		RunID:     runID,
+		Command:   command,
+		Events:    events,
+		Files:     ChangedFiles(events),
+		StartTime: time.Now(),
+	}
+}
+
+// Status 返回执行结果的简短描述
+func (r *RunResult) Status() string {
+	switch {
+	case r.TimedOut:
+		return "超时"
+	case r.Terminated:
+		return "已终止"
+	case r.Succeeded():
+		return "成功"
+	default:
+		return "失败"
+	}
+}
+
+// Succeeded 判断命令是否正常退出且退出码为 0
+func (r *RunResult) Succeeded() bool {
+	return !r.Terminated && !r.TimedOut && r.Err == nil && r.Signal == "" && r.ExitCode == 0
+}
@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskStatus 表示流水线中任务的执行状态
+type TaskStatus int
+
+const (
+	// TaskRunning 任务正在执行
+	TaskRunning TaskStatus = iota
+	// TaskSucceeded 任务执行成功
+	TaskSucceeded
+	// TaskFailed 任务执行失败
+	TaskFailed
+	// TaskSkipped 因依赖失败或流水线被取消而跳过
+	TaskSkipped
+	// TaskCanceled 任务执行过程中被取消
+	TaskCanceled
+	// TaskTimedOut 任务执行超时被终止
+	TaskTimedOut
+)
+
+// String 返回任务状态的显示名称
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskRunning:
+		return "运行中"
+	case TaskSucceeded:
+		return "成功"
+	case TaskFailed:
+		return "失败"
+	case TaskSkipped:
+		return "跳过"
+	case TaskCanceled:
+		return "已取消"
+	case TaskTimedOut:
+		return "超时"
+	default:
+		return "未知"
+	}
+}
+
+// Task 表示任务流水线中的一个命名任务
+type Task struct {
+	// 任务名称，在流水线中唯一
+	Name string
+	// 任务执行的命令
+	Command string
+	// 依赖的任务名称，依赖全部成功后才会执行
+	DependsOn []string
+	// 任务的最长执行时间，为 0 时使用全局的 timeout 设置
+	Timeout time.Duration
+}
+
+// SortTasks 校验任务依赖关系并按拓扑顺序返回任务
+// 名称重复、依赖不存在或存在循环依赖时返回错误
+func SortTasks(tasks []Task) ([]Task, error) {
+	byName := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		if task.Name == "" {
+			return nil, fmt.Errorf("任务名称不能为空")
+		}
+		if task.Command == "" {
+			return nil, fmt.Errorf("任务 %s 的命令不能为空", task.Name)
+		}
+		if task.Timeout < 0 {
+			return nil, fmt.Errorf("任务 %s 的 timeout_ms 不能为负数", task.Name)
+		}
+		if _, ok := byName[task.Name]; ok {
+			return nil, fmt.Errorf("任务名称重复: %s", task.Name)
+		}
+		byName[task.Name] = task
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(tasks))
+	sorted := make([]Task, 0, len(tasks))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("任务存在循环依赖: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+
+		state[name] = visiting
+		task := byName[name]
+		for _, dep := range task.DependsOn {
+			if _, ok := byName[dep]; !ok {
+				return fmt.Errorf("任务 %s 依赖的任务不存在: %s", name, dep)
+			}
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		sorted = append(sorted, task)
+		return nil
+	}
+
+	for _, task := range tasks {
+		if err := visit(task.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSortTasksOrder 依赖的任务排在依赖它的任务之前，没有依赖关系的任务保持配置中的顺序
+func TestSortTasksOrder(t *testing.T) {
+	tasks := []Task{
+		{Name: "test", Command: "go test ./...", DependsOn: []string{"build"}},
+		{Name: "lint", Command: "go vet ./..."},
+		{Name: "build", Command: "go build ./...", DependsOn: []string{"generate"}},
+		{Name: "generate", Command: "go generate ./..."},
+	}
+
+	sorted, err := SortTasks(tasks)
+	if err != nil {
+		t.Fatalf("SortTasks() error = %v", err)
+	}
+
+	var names []string
+	for _, task := range sorted {
+		names = append(names, task.Name)
+	}
+	want := "generate,build,test,lint"
+	if got := strings.Join(names, ","); got != want {
+		t.Errorf("SortTasks() order = %s, want %s", got, want)
+	}
+}
+
+// TestSortTasksErrors 无效的任务和依赖关系返回对应的错误
+func TestSortTasksErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []Task
+		want  string
+	}{
+		{
+			name:  "empty name",
+			tasks: []Task{{Command: "make"}},
+			want:  "任务名称不能为空",
+		},
+		{
+			name:  "empty command",
+			tasks: []Task{{Name: "build"}},
+			want:  "任务 build 的命令不能为空",
+		},
+		{
+			name:  "negative timeout",
+			tasks: []Task{{Name: "build", Command: "make", Timeout: -1}},
+			want:  "任务 build 的 timeout_ms 不能为负数",
+		},
+		{
+			name:  "duplicate name",
+			tasks: []Task{{Name: "build", Command: "make"}, {Name: "build", Command: "make all"}},
+			want:  "任务名称重复: build",
+		},
+		{
+			name:  "missing dependency",
+			tasks: []Task{{Name: "test", Command: "make test", DependsOn: []string{"build"}}},
+			want:  "任务 test 依赖的任务不存在: build",
+		},
+		{
+			name: "cycle",
+			tasks: []Task{
+				{Name: "a", Command: "echo a", DependsOn: []string{"b"}},
+				{Name: "b", Command: "echo b", DependsOn: []string{"c"}},
+				{Name: "c", Command: "echo c", DependsOn: []string{"a"}},
+			},
+			want: "任务存在循环依赖: a -> b -> c -> a",
+		},
+		{
+			name:  "self dependency",
+			tasks: []Task{{Name: "a", Command: "echo a", DependsOn: []string{"a"}}},
+			want:  "任务存在循环依赖: a -> a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SortTasks(tt.tasks)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("SortTasks() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
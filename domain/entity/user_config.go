@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// UserConfig 表示用户级的全局配置，保存颜色、防抖、通知钩子等个人偏好
+// 作为所有项目配置的默认值，项目配置中设置的配置项优先
+type UserConfig struct {
+	// 是否输出彩色信息，为 nil 时沿用项目配置
+	Color *bool
+	// 防抖静默期
+	Debounce time.Duration
+	// 文件监控后端
+	Backend WatcherBackend
+	// 轮询后端的扫描间隔
+	PollInterval time.Duration
+	// 命令执行选项
+	CommandOptions
+	// 生命周期钩子命令，如命令失败时发送桌面通知
+	Hooks Hooks
+}
+
+// Validate 校验用户配置
+func (u *UserConfig) Validate() error {
+	if u.Backend != "" {
+		if err := ValidateBackend(u.Backend); err != nil {
+			return err
+		}
+	}
+	return u.CommandOptions.Validate()
+}
@@ -8,4 +8,8 @@ type ConfigRepository interface {
 	LoadConfig(path string) (*entity.WatchConfig, error)
 	// SaveConfig 保存配置到指定路径
 	SaveConfig(config *entity.WatchConfig, path string) error
+	// CheckConfig 检查指定路径的配置文件，一次返回其中的所有问题
+	CheckConfig(path string) ([]entity.ConfigProblem, error)
+	// LoadUserConfig 从指定路径加载用户级的全局配置
+	LoadUserConfig(path string) (*entity.UserConfig, error)
 }
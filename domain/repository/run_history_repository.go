@@ -0,0 +1,11 @@
+package repository
+
+import "github.com/watchs/domain/entity"
+
+// RunHistoryRepository 定义命令执行历史的仓储接口
+type RunHistoryRepository interface {
+	// Append 将一次执行结果追加到指定目录的执行历史中
+	Append(dir string, result *entity.RunResult) error
+	// List 按开始时间顺序返回指定目录中的所有执行历史
+	List(dir string) ([]*entity.RunResult, error)
+}
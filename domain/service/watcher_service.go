@@ -10,12 +10,24 @@ type WatcherService interface {
 	Stop() error
 	// OnFileEvent 注册文件事件处理函数
 	OnFileEvent(handler func(event *entity.FileEvent) error)
+	// UpdateConfig 在不停止监控的情况下切换到新的监控目录和过滤规则
+	UpdateConfig(config *entity.WatchConfig) error
 }
 
 // CommandExecutor 定义命令执行服务的接口
 type CommandExecutor interface {
-	// Execute 执行命令
-	Execute(command string, workDir string) error
+	// Execute 执行命令，events 为触发本次执行的文件事件（初始执行时为空）
+	Execute(command string, workDir string, events []*entity.FileEvent) error
 	// Terminate 终止正在执行的命令
 	Terminate() error
+	// OnRunComplete 注册命令执行结束后的处理函数
+	OnRunComplete(handler func(result *entity.RunResult))
+	// OnReady 注册命令通过就绪检查后的处理函数
+	OnReady(handler func(result *entity.RunResult))
+}
+
+// HookRunner 定义生命周期钩子的执行接口
+type HookRunner interface {
+	// RunHook 同步执行钩子命令，result 为触发钩子的执行（on_start 和 on_stop 时为 nil）
+	RunHook(hook entity.HookType, command string, workDir string, result *entity.RunResult) error
 }
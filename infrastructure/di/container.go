@@ -9,9 +9,13 @@ import (
 
 // Container 依赖注入容器
 type Container struct {
+	configPath              string
+	userConfigPath          string
 	configRepo              repository.ConfigRepository
+	historyRepo             repository.RunHistoryRepository
 	configApplicationService interfaces.ConfigApplicationService
 	watchApplicationService  interfaces.WatchApplicationService
+	historyApplicationService interfaces.HistoryApplicationService
 }
 
 // NewContainer 创建新的依赖注入容器
@@ -24,11 +28,20 @@ func NewContainer() *Container {
 // initializeDependencies 初始化所有依赖关系
 func (c *Container) initializeDependencies() {
 	// 基础设施层
-	c.configRepo = persistence.NewJsonConfigRepository()
+	c.configPath = persistence.DiscoverConfigFile(".")
+	c.userConfigPath = persistence.FindUserConfigFile()
+	c.configRepo = persistence.NewFormatConfigRepository()
+	c.historyRepo = persistence.NewJournalRunHistoryRepository()
 
 	// 应用服务层
-	c.configApplicationService = services.NewConfigApplicationService(c.configRepo)
-	c.watchApplicationService = services.NewWatchApplicationService(c.configApplicationService)
+	c.configApplicationService = services.NewConfigApplicationService(c.configRepo, c.userConfigPath)
+	c.watchApplicationService = services.NewWatchApplicationService(c.configApplicationService, c.historyRepo)
+	c.historyApplicationService = services.NewHistoryApplicationService(c.historyRepo)
+}
+
+// GetConfigPath 获取当前目录或上级目录中的配置文件路径（watchs.json、watchs.yaml 或 watchs.toml）
+func (c *Container) GetConfigPath() string {
+	return c.configPath
 }
 
 // GetConfigRepository 获取配置仓储
@@ -45,3 +58,8 @@ func (c *Container) GetConfigApplicationService() interfaces.ConfigApplicationSe
 func (c *Container) GetWatchApplicationService() interfaces.WatchApplicationService {
 	return c.watchApplicationService
 }
+
+// GetHistoryApplicationService 获取执行历史应用服务
+func (c *Container) GetHistoryApplicationService() interfaces.HistoryApplicationService {
+	return c.historyApplicationService
+}
@@ -0,0 +1,211 @@
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/watchs/internal/glob"
+)
+
+const (
+	// GitIgnoreFile Git 的忽略规则文件
+	GitIgnoreFile = ".gitignore"
+	// WatchsIgnoreFile watchs 专用的忽略规则文件，语法与 .gitignore 相同
+	WatchsIgnoreFile = ".watchsignore"
+)
+
+// rule 表示忽略文件中的一条规则
+type rule struct {
+	// 去掉前缀和后缀标记后的模式
+	pattern string
+	// 以 ! 开头的规则，重新包含之前被忽略的路径
+	negate bool
+	// 以 / 结尾的规则，只匹配目录
+	dirOnly bool
+	// 模式中包含 /，相对于规则所在目录匹配；否则匹配任意层级的文件名
+	anchored bool
+}
+
+// Matcher 根据目录树中的 .gitignore 风格文件判断路径是否被忽略
+// 每个目录的规则在第一次用到时加载并缓存，嵌套目录中的规则优先级更高
+type Matcher struct {
+	root      string
+	fileNames []string
+	mu        sync.Mutex
+	rules     map[string][]rule
+}
+
+// NewMatcher 创建忽略规则匹配器，fileNames 为要读取的忽略文件名
+func NewMatcher(root string, fileNames ...string) *Matcher {
+	return &Matcher{
+		root:      root,
+		fileNames: fileNames,
+		rules:     make(map[string][]rule),
+	}
+}
+
+// IsIgnoreFile 判断给定路径是否是匹配器读取的忽略文件
+func (m *Matcher) IsIgnoreFile(filePath string) bool {
+	base := filepath.Base(filePath)
+	for _, name := range m.fileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Invalidate 丢弃某个目录的缓存规则，在忽略文件变化后调用
+func (m *Matcher) Invalidate(dir string) {
+	rel, ok := m.relPath(dir)
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rules, rel)
+}
+
+// Match 判断路径是否被忽略
+// 与 Git 一致，只要某一级父目录被忽略，其下的所有内容都被忽略
+func (m *Matcher) Match(filePath string, isDir bool) bool {
+	rel, ok := m.relPath(filePath)
+	if !ok || rel == "" {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.matchPath(parts[:i], true) {
+			return true
+		}
+	}
+	return m.matchPath(parts, isDir)
+}
+
+// matchPath 按从根目录到父目录的顺序应用各目录的规则，最后一条匹配的规则生效
+func (m *Matcher) matchPath(parts []string, isDir bool) bool {
+	// Git 自身的目录始终忽略
+	if parts[len(parts)-1] == ".git" {
+		return true
+	}
+
+	ignored := false
+	for depth := 0; depth < len(parts); depth++ {
+		dir := strings.Join(parts[:depth], "/")
+		rel := strings.Join(parts[depth:], "/")
+		for _, r := range m.rulesFor(dir) {
+			if r.match(rel, parts[len(parts)-1], isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// rulesFor 获取目录的规则，必要时从磁盘加载
+func (m *Matcher) rulesFor(dir string) []rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if rules, ok := m.rules[dir]; ok {
+		return rules
+	}
+
+	var rules []rule
+	for _, name := range m.fileNames {
+		rules = append(rules, parseFile(filepath.Join(m.root, filepath.FromSlash(dir), name))...)
+	}
+	m.rules[dir] = rules
+	return rules
+}
+
+// relPath 计算相对于根目录的路径，路径不在根目录下时返回 false
+func (m *Matcher) relPath(filePath string) (string, bool) {
+	rel, err := filepath.Rel(m.root, filePath)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		return "", true
+	}
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// match 判断相对于规则目录的路径是否匹配该规则
+func (r rule) match(rel, name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.anchored {
+		return glob.Match(r.pattern, rel)
+	}
+	matched, err := path.Match(r.pattern, name)
+	return err == nil && matched
+}
+
+// parseFile 解析忽略文件，文件不存在时返回空规则
+func parseFile(filePath string) []rule {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []rule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if r, ok := parseLine(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseLine 解析单行规则，空行和注释返回 false
+func parseLine(line string) (rule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+
+	// 去掉未转义的行尾空格
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.HasPrefix(line, "/") {
+		r.anchored = true
+		line = strings.TrimLeft(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		r.anchored = true
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	r.pattern = line
+	return r, true
+}
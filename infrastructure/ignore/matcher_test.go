@@ -0,0 +1,123 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeIgnoreFile 在 root 下的 dir 目录中写入 .gitignore
+func writeIgnoreFile(t *testing.T, root, dir, content string) {
+	t.Helper()
+	full := filepath.Join(root, filepath.FromSlash(dir))
+	if err := os.MkdirAll(full, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(full, GitIgnoreFile), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMatcherMatch 覆盖仅目录规则、取反规则、锚定规则、** 和嵌套的忽略文件
+func TestMatcherMatch(t *testing.T) {
+	root := t.TempDir()
+	writeIgnoreFile(t, root, "", "# 构建产物\nbuild/\n*.log\n!keep.log\n/dist\ndocs/**/*.tmp\nsecret\n")
+	writeIgnoreFile(t, root, "sub", "!secret\n*.bak\n")
+	matcher := NewMatcher(root, GitIgnoreFile)
+
+	tests := []struct {
+		name  string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"dir-only rule matches directory", "build", true, true},
+		{"dir-only rule skips file", "build", false, false},
+		{"dir-only rule at any depth", "pkg/build", true, true},
+		{"child of ignored directory", "build/out/app.bin", false, true},
+		{"file name pattern", "app.log", false, true},
+		{"file name pattern at any depth", "pkg/debug.log", false, true},
+		{"negation re-includes", "keep.log", false, false},
+		{"negation at any depth", "pkg/keep.log", false, false},
+		{"anchored rule at root", "dist", true, true},
+		{"child of anchored directory", "dist/app.js", false, true},
+		{"anchored rule not nested", "web/dist", true, false},
+		{"child of nested directory with anchored name", "web/dist/app.js", false, false},
+		{"double star nested", "docs/a/b/draft.tmp", false, true},
+		{"double star zero depth", "docs/draft.tmp", false, true},
+		{"double star other directory", "notes/docs/draft.tmp", false, false},
+		{"root rule", "secret", false, true},
+		{"root rule in other directory", "pkg/secret", false, true},
+		{"nested file overrides root", "sub/secret", false, false},
+		{"nested file overrides root below it", "sub/deep/secret", false, false},
+		{"nested rule", "sub/old.bak", false, true},
+		{"nested rule not applied outside", "old.bak", false, false},
+		{"git directory", ".git", true, true},
+		{"inside git directory", ".git/objects/ab", false, true},
+		{"unmatched file", "main.go", false, false},
+		{"root itself", "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(root, filepath.FromSlash(tt.path))
+			if got := matcher.Match(path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+
+	if matcher.Match(filepath.Join(filepath.Dir(root), "app.log"), false) {
+		t.Error("Match() = true for a path outside the root, want false")
+	}
+}
+
+// TestMatcherInvalidate 忽略文件变化后丢弃缓存的规则
+func TestMatcherInvalidate(t *testing.T) {
+	root := t.TempDir()
+	writeIgnoreFile(t, root, "", "*.log\n")
+	matcher := NewMatcher(root, GitIgnoreFile)
+	path := filepath.Join(root, "app.log")
+	if !matcher.Match(path, false) {
+		t.Fatal("Match() = false before the change, want true")
+	}
+
+	writeIgnoreFile(t, root, "", "*.tmp\n")
+	if !matcher.Match(path, false) {
+		t.Error("Match() = false before Invalidate, want the cached rules")
+	}
+	matcher.Invalidate(root)
+	if matcher.Match(path, false) {
+		t.Error("Match() = true after Invalidate, want false")
+	}
+}
+
+// TestParseLine 覆盖注释、转义、行尾空格和规则标记
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want rule
+		ok   bool
+	}{
+		{line: ""},
+		{line: "# comment"},
+		{line: "   "},
+		{line: "/"},
+		{line: "*.log  ", want: rule{pattern: "*.log"}, ok: true},
+		{line: "a\\ ", want: rule{pattern: "a\\ "}, ok: true},
+		{line: "\\#file", want: rule{pattern: "#file"}, ok: true},
+		{line: "\\!important", want: rule{pattern: "!important"}, ok: true},
+		{line: "!keep.log", want: rule{pattern: "keep.log", negate: true}, ok: true},
+		{line: "build/", want: rule{pattern: "build", dirOnly: true}, ok: true},
+		{line: "/dist", want: rule{pattern: "dist", anchored: true}, ok: true},
+		{line: "docs/**/*.tmp", want: rule{pattern: "docs/**/*.tmp", anchored: true}, ok: true},
+		{line: "!/out/\r", want: rule{pattern: "out", negate: true, dirOnly: true, anchored: true}, ok: true},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseLine(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseLine(%q) = %+v, %v, want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package persistence
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/watchs/domain/entity"
+)
+
+// maxKeySuggestionDistance 未知配置项与已知配置项的编辑距离不超过该值时给出建议
+const maxKeySuggestionDistance = 2
+
+// configChecker 检查配置树，收集所有问题而不是在第一个问题处停止
+type configChecker struct {
+	// 配置中相对路径的基准目录，为配置文件所在的目录
+	baseDir  string
+	problems []entity.ConfigProblem
+}
+
+// checkConfigTree 检查配置树中的结构问题（未知的配置项、类型错误）、内容问题和实体校验问题
+// 没有发现问题时再按配置仓储的方式完整解析一次，报告其余需要合并配置后才能发现的校验错误
+func checkConfigTree(root *configNode, baseDir string, parse func() (*entity.WatchConfig, error)) []entity.ConfigProblem {
+	checker := &configChecker{baseDir: baseDir}
+	checker.checkSchema(root, reflect.TypeOf(configDTO{}), "")
+	if root.kind == nodeObject {
+		checker.checkContent(root)
+	}
+
+	if len(checker.problems) == 0 {
+		if _, err := parse(); err != nil {
+			checker.problems = append(checker.problems, entity.ConfigProblem{Message: err.Error()})
+		}
+	}
+
+	// 按在文件中的位置排列，位置未知的问题排在最后
+	sort.SliceStable(checker.problems, func(i, j int) bool {
+		a, b := checker.problems[i], checker.problems[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return checker.problems
+}
+
+// add 记录节点上的问题
+func (c *configChecker) add(node *configNode, path string, format string, args ...any) {
+	problem := entity.ConfigProblem{Path: path, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		problem.Line, problem.Column = node.line, node.column
+	}
+	c.problems = append(c.problems, problem)
+}
+
+// checkSchema 按DTO的结构检查节点的键和值类型，null 视为未设置
+func (c *configChecker) checkSchema(node *configNode, t reflect.Type, path string) {
+	if node.kind == nodeNull {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		c.checkSchema(node, t.Elem(), path)
+	case reflect.Struct:
+		if !c.expectKind(node, path, nodeObject) {
+			return
+		}
+		fields := dtoFields(t)
+		seen := make(map[string]bool)
+		for _, field := range node.fields {
+			fieldPath := joinConfigPath(path, field.key)
+			key := &configNode{line: field.line, column: field.column}
+			fieldType, ok := fields[field.key]
+			if !ok {
+				c.add(key, fieldPath, "%s", unknownKeyMessage(field.key, fields))
+				continue
+			}
+			if seen[field.key] {
+				c.add(key, fieldPath, "重复的配置项 %q", field.key)
+			}
+			seen[field.key] = true
+			c.checkSchema(field.value, fieldType, fieldPath)
+		}
+	case reflect.Map:
+		if !c.expectKind(node, path, nodeObject) {
+			return
+		}
+		for _, field := range node.fields {
+			c.checkSchema(field.value, t.Elem(), joinConfigPath(path, field.key))
+		}
+	case reflect.Slice:
+		if !c.expectKind(node, path, nodeArray) {
+			return
+		}
+		for i, item := range node.items {
+			c.checkSchema(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.String:
+		c.expectKind(node, path, nodeString)
+	case reflect.Int:
+		c.expectKind(node, path, nodeInteger)
+	case reflect.Bool:
+		c.expectKind(node, path, nodeBool)
+	}
+}
+
+// expectKind 检查节点的值类型，不符合时记录类型错误
+func (c *configChecker) expectKind(node *configNode, path string, kind nodeKind) bool {
+	if node.kind == kind || (kind == nodeBool && node.boolLike) {
+		return true
+	}
+	c.add(node, path, "类型错误: 应为%s，实际为%s", kind, node.kind)
+	return false
+}
+
+// checkContent 检查监控目录、命令、文件类型和排除项的内容
+func (c *configChecker) checkContent(root *configNode) {
+	watchDir := ""
+	if node := root.field("watch_dir"); node != nil {
+		watchDir = c.checkWatchDir(node, "watch_dir")
+	} else {
+		c.add(root, "watch_dir", "缺少监控目录")
+	}
+	if len(root.field("tasks").elements()) == 0 && len(root.field("rules").elements()) == 0 {
+		c.checkCommand(root, root.field("command"), "command", "执行命令不能为空")
+	}
+	c.checkPipelineCommands(root, "")
+	c.checkFileTypes(root, "")
+	c.checkEntities(root, "")
+	c.checkForwardStdin(root)
+	c.checkTaskOptions(root)
+	if watchDir != "" {
+		c.checkExcludes(root, watchDir)
+	}
+	c.checkProfiles(root.field("profiles"))
+}
+
+// checkProfiles 检查配置方案中设置的监控目录、命令和文件类型
+// 配置方案未设置的配置项沿用基础配置，因此不要求其中包含监控目录和命令
+func (c *configChecker) checkProfiles(profiles *configNode) {
+	if profiles == nil || profiles.kind != nodeObject {
+		return
+	}
+	for _, field := range profiles.fields {
+		profile := field.value
+		if profile.kind != nodeObject {
+			continue
+		}
+		prefix := joinConfigPath("profiles", field.key)
+		if node := profile.field("watch_dir"); node != nil {
+			c.checkWatchDir(node, joinConfigPath(prefix, "watch_dir"))
+		}
+		if command := profile.field("command"); command != nil && command.kind == nodeString && strings.TrimSpace(command.text) == "" {
+			c.add(command, joinConfigPath(prefix, "command"), "执行命令不能为空")
+		}
+		c.checkPipelineCommands(profile, prefix)
+		c.checkFileTypes(profile, prefix)
+		c.checkEntities(profile, prefix)
+	}
+}
+
+// checkWatchDir 检查监控目录是否存在，返回其绝对路径，目录不可用时返回空字符串
+func (c *configChecker) checkWatchDir(node *configNode, path string) string {
+	if node.kind != nodeString {
+		return ""
+	}
+	if strings.TrimSpace(node.text) == "" {
+		c.add(node, path, "监控目录不能为空")
+		return ""
+	}
+
+	absPath, err := filepath.Abs(entity.ResolvePath(c.baseDir, node.text))
+	if err != nil {
+		c.add(node, path, "获取绝对路径失败: %v", err)
+		return ""
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		c.add(node, path, "监控目录不存在: %s", absPath)
+		return ""
+	}
+	if !info.IsDir() {
+		c.add(node, path, "监控目录不是目录: %s", absPath)
+		return ""
+	}
+	return absPath
+}
+
+// checkPipelineCommands 检查对象中任务和规则的命令是否为空，prefix 为对象在配置中的路径
+func (c *configChecker) checkPipelineCommands(object *configNode, prefix string) {
+	for i, task := range object.field("tasks").elements() {
+		c.checkCommand(task, task.field("command"), fmt.Sprintf("%s[%d].command", joinConfigPath(prefix, "tasks"), i), "任务的命令不能为空")
+	}
+	for i, rule := range object.field("rules").elements() {
+		c.checkCommand(rule, rule.field("command"), fmt.Sprintf("%s[%d].command", joinConfigPath(prefix, "rules"), i), "规则的命令不能为空")
+	}
+}
+
+// checkCommand 检查命令是否为空，命令未设置时问题位置为其所在的对象
+func (c *configChecker) checkCommand(parent, command *configNode, path, message string) {
+	if parent.kind != nodeObject {
+		return
+	}
+	if command == nil {
+		c.add(parent, path, "%s", message)
+		return
+	}
+	if command.kind == nodeString && strings.TrimSpace(command.text) == "" {
+		c.add(command, path, "%s", message)
+	}
+}
+
+// checkFileTypes 检查对象中的文件类型是否以 "." 开头，prefix 为对象在配置中的路径
+func (c *configChecker) checkFileTypes(object *configNode, prefix string) {
+	path := joinConfigPath(prefix, "file_types")
+	for i, item := range object.field("file_types").elements() {
+		if item.kind != nodeString {
+			continue
+		}
+		if !strings.HasPrefix(item.text, ".") {
+			c.add(item, fmt.Sprintf("%s[%d]", path, i), "文件类型 %q 应以 \".\" 开头，如 %q", item.text, "."+item.text)
+		}
+	}
+}
+
+// checkExcludes 检查 exclude_paths 和 exclude 中的每一项是否匹配监控目录中的文件或目录
+func (c *configChecker) checkExcludes(root *configNode, watchDir string) {
+	excludePaths := stringElements(root.field("exclude_paths"), "exclude_paths")
+
+	// 语法错误的模式已由 checkEntities 报告，不参与匹配检查
+	var excludes []stringElement
+	for _, element := range stringElements(root.field("exclude"), "exclude") {
+		if entity.ValidatePattern(element.value) == nil {
+			excludes = append(excludes, element)
+		}
+	}
+	if len(excludePaths) == 0 && len(excludes) == 0 {
+		return
+	}
+
+	config, err := entity.NewWatchConfig(watchDir, nil, elementValues(excludePaths), "")
+	if err != nil {
+		return
+	}
+	config.BaseDir = c.baseDir
+	config.Exclude = elementValues(excludes)
+	unusedPaths, unusedExcludes, err := config.UnusedExcludes()
+	if err != nil {
+		c.add(root.field("watch_dir"), "watch_dir", "遍历监控目录失败: %v", err)
+		return
+	}
+
+	for _, i := range unusedPaths {
+		element := excludePaths[i]
+		c.add(element.node, element.path, "排除路径 %q 没有匹配任何文件或目录", element.value)
+	}
+	for _, i := range unusedExcludes {
+		element := excludes[i]
+		c.add(element.node, element.path, "排除模式 %q 没有匹配任何文件或目录", element.value)
+	}
+}
+
+// checkEntities 按实体的校验规则检查对象中的匹配模式、任务、规则、命令选项和监控后端
+// 与结构检查同时进行，问题定位到对应的节点，prefix 为对象在配置中的路径
+func (c *configChecker) checkEntities(object *configNode, prefix string) {
+	c.checkPatterns(object.field("include"), joinConfigPath(prefix, "include"))
+	c.checkPatterns(object.field("exclude"), joinConfigPath(prefix, "exclude"))
+	c.checkTasks(object.field("tasks"), joinConfigPath(prefix, "tasks"))
+	for i, rule := range object.field("rules").elements() {
+		if rule.kind != nodeObject {
+			continue
+		}
+		path := fmt.Sprintf("%s[%d]", joinConfigPath(prefix, "rules"), i)
+		patterns := rule.field("patterns")
+		if patterns == nil {
+			c.add(rule, joinConfigPath(path, "patterns"), "规则的匹配模式不能为空")
+		} else if patterns.kind == nodeArray && len(patterns.items) == 0 {
+			c.add(patterns, joinConfigPath(path, "patterns"), "规则的匹配模式不能为空")
+		}
+		c.checkPatterns(patterns, joinConfigPath(path, "patterns"))
+		c.checkCommandOptions(rule, path)
+	}
+	c.checkCommandOptions(object, prefix)
+
+	if backend := object.field("backend"); backend != nil && backend.kind == nodeString {
+		if err := entity.ValidateBackend(entity.WatcherBackend(backend.text)); err != nil {
+			c.add(backend, joinConfigPath(prefix, "backend"), "%v", err)
+		}
+	}
+}
+
+// checkPatterns 检查数组中的每个匹配模式的语法
+func (c *configChecker) checkPatterns(node *configNode, path string) {
+	for _, element := range stringElements(node, path) {
+		if err := entity.ValidatePattern(element.value); err != nil {
+			c.add(element.node, element.path, "无效的匹配模式 %q: %v", element.value, err)
+		}
+	}
+}
+
+// checkTasks 检查任务名称和依赖关系，如重复的名称、不存在的依赖和循环依赖
+func (c *configChecker) checkTasks(node *configNode, path string) {
+	if node == nil || node.kind != nodeArray {
+		return
+	}
+	var dtos []taskDTO
+	if err := node.decode(&dtos); err != nil {
+		return
+	}
+	tasks := tasksToEntity(dtos)
+	for i := range tasks {
+		// 命令为空的任务已由 checkPipelineCommands 报告，这里只检查名称和依赖关系
+		if strings.TrimSpace(tasks[i].Command) == "" {
+			tasks[i].Command = "-"
+		}
+	}
+	if _, err := entity.SortTasks(tasks); err != nil {
+		c.add(node, path, "%v", err)
+	}
+}
+
+// checkCommandOptions 逐项检查对象中的命令执行选项，问题定位到对应配置项的值
+func (c *configChecker) checkCommandOptions(object *configNode, prefix string) {
+	options := dtoFields(reflect.TypeOf(commandOptionsDTO{}))
+	for _, field := range object.fields {
+		if _, ok := options[field.key]; !ok || field.value.kind == nodeNull {
+			continue
+		}
+		single := &configNode{kind: nodeObject, fields: []configField{field}}
+		var dto commandOptionsDTO
+		if err := single.decode(&dto); err != nil {
+			continue
+		}
+		if err := dto.toEntity().Validate(); err != nil {
+			c.add(field.value, joinConfigPath(prefix, field.key), "%v", err)
+		}
+	}
+}
+
+// checkForwardStdin 检查 forward_stdin 与命令、任务和 changed_files_stdin 的组合
+func (c *configChecker) checkForwardStdin(root *configNode) {
+	forward := root.field("forward_stdin")
+	if forward == nil || forward.scalar != true {
+		return
+	}
+	command := root.field("command")
+	if command == nil || (command.kind == nodeString && command.text == "") || len(root.field("tasks").elements()) > 0 {
+		c.add(forward, "forward_stdin", "forward_stdin 需要设置 command，输入只转发给主命令，不支持任务流水线")
+	}
+	if mode := root.field("changed_files_stdin"); mode != nil && mode.kind == nodeString && mode.text != "" {
+		c.add(forward, "forward_stdin", "forward_stdin 不能与 changed_files_stdin 同时使用")
+	}
+}
+
+// checkTaskOptions 检查设置了任务流水线时不支持的命令执行选项
+func (c *configChecker) checkTaskOptions(root *configNode) {
+	if len(root.field("tasks").elements()) == 0 {
+		return
+	}
+	if supervise := root.field("supervise"); supervise != nil && supervise.scalar == true {
+		c.add(supervise, "supervise", "supervise 不能与 tasks 同时使用，任务流水线不支持自动重启")
+	}
+	if ready := root.field("ready"); ready != nil {
+		c.add(ready, "ready", "ready 不能与 tasks 同时使用，任务流水线不支持就绪检查")
+	}
+	if signal := root.field("reload_signal"); signal != nil && signal.kind == nodeString && signal.text != "" {
+		c.add(signal, "reload_signal", "reload_signal 不能与 tasks 同时使用，任务流水线不支持发送重新加载信号")
+	}
+}
+
+// stringElement 是数组中的一个字符串元素
+type stringElement struct {
+	value string
+	path  string
+	node  *configNode
+}
+
+// stringElements 返回数组中的字符串元素，其他类型的元素已在结构检查中报告
+func stringElements(node *configNode, path string) []stringElement {
+	var elements []stringElement
+	for i, item := range node.elements() {
+		if item.kind == nodeString {
+			elements = append(elements, stringElement{value: item.text, path: fmt.Sprintf("%s[%d]", path, i), node: item})
+		}
+	}
+	return elements
+}
+
+// elementValues 返回元素的值
+func elementValues(elements []stringElement) []string {
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		values = append(values, element.value)
+	}
+	return values
+}
+
+// dtoFields 返回DTO结构体按 json 标签命名的字段类型，展开嵌入的结构体
+func dtoFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			for name, fieldType := range dtoFields(field.Type) {
+				fields[name] = fieldType
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// unknownKeyMessage 返回未知配置项的问题描述，有拼写相近的配置项时给出建议
+func unknownKeyMessage(key string, fields map[string]reflect.Type) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suggestion := ""
+	best := maxKeySuggestionDistance + 1
+	for _, name := range names {
+		if distance := editDistance(key, name); distance < best {
+			best = distance
+			suggestion = name
+		}
+	}
+	if suggestion == "" {
+		return fmt.Sprintf("未知的配置项 %q", key)
+	}
+	return fmt.Sprintf("未知的配置项 %q，是否应为 %q", key, suggestion)
+}
+
+// editDistance 计算两个字符串之间的编辑距离
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,420 @@
+package persistence
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/watchs/domain/entity"
+)
+
+// configDTO 是配置的数据传输对象
+type configDTO struct {
+	WatchDir          string    `json:"watch_dir" yaml:"watch_dir" toml:"watch_dir"`
+	FileTypes         []string  `json:"file_types" yaml:"file_types" toml:"file_types"`
+	ExcludePaths      []string  `json:"exclude_paths" yaml:"exclude_paths" toml:"exclude_paths"`
+	Include           []string  `json:"include,omitempty" yaml:"include,omitempty" toml:"include,omitempty"`
+	Exclude           []string  `json:"exclude,omitempty" yaml:"exclude,omitempty" toml:"exclude,omitempty"`
+	Command           string    `json:"command" yaml:"command" toml:"command"`
+	Tasks             []taskDTO `json:"tasks,omitempty" yaml:"tasks,omitempty" toml:"tasks,omitempty"`
+	Rules             []ruleDTO `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	UseGitignore      *bool     `json:"use_gitignore,omitempty" yaml:"use_gitignore,omitempty" toml:"use_gitignore,omitempty"`
+	Backend           string    `json:"backend,omitempty" yaml:"backend,omitempty" toml:"backend,omitempty"`
+	PollIntervalMs    int       `json:"poll_interval_ms,omitempty" yaml:"poll_interval_ms,omitempty" toml:"poll_interval_ms,omitzero"`
+	Hooks             *hooksDTO `json:"hooks,omitempty" yaml:"hooks,omitempty" toml:"hooks,omitempty"`
+	ForwardStdin      bool      `json:"forward_stdin,omitempty" yaml:"forward_stdin,omitempty" toml:"forward_stdin,omitempty"`
+	StdinEscape       string    `json:"stdin_escape,omitempty" yaml:"stdin_escape,omitempty" toml:"stdin_escape,omitempty"`
+	DebounceMs        int       `json:"debounce_ms,omitempty" yaml:"debounce_ms,omitempty" toml:"debounce_ms,omitzero"`
+	Color             *bool     `json:"color,omitempty" yaml:"color,omitempty" toml:"color,omitempty"`
+	commandOptionsDTO `yaml:",inline"`
+
+	Profiles map[string]profileDTO `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+}
+
+// profileDTO 是配置方案的数据传输对象，未设置的配置项沿用基础配置
+type profileDTO struct {
+	WatchDir          string    `json:"watch_dir,omitempty" yaml:"watch_dir,omitempty" toml:"watch_dir,omitempty"`
+	FileTypes         []string  `json:"file_types,omitempty" yaml:"file_types,omitempty" toml:"file_types,omitempty"`
+	ExcludePaths      []string  `json:"exclude_paths,omitempty" yaml:"exclude_paths,omitempty" toml:"exclude_paths,omitempty"`
+	Include           []string  `json:"include,omitempty" yaml:"include,omitempty" toml:"include,omitempty"`
+	Exclude           []string  `json:"exclude,omitempty" yaml:"exclude,omitempty" toml:"exclude,omitempty"`
+	Command           string    `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	Tasks             []taskDTO `json:"tasks,omitempty" yaml:"tasks,omitempty" toml:"tasks,omitempty"`
+	Rules             []ruleDTO `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	UseGitignore      *bool     `json:"use_gitignore,omitempty" yaml:"use_gitignore,omitempty" toml:"use_gitignore,omitempty"`
+	Backend           string    `json:"backend,omitempty" yaml:"backend,omitempty" toml:"backend,omitempty"`
+	PollIntervalMs    int       `json:"poll_interval_ms,omitempty" yaml:"poll_interval_ms,omitempty" toml:"poll_interval_ms,omitzero"`
+	Hooks             *hooksDTO `json:"hooks,omitempty" yaml:"hooks,omitempty" toml:"hooks,omitempty"`
+	ForwardStdin      *bool     `json:"forward_stdin,omitempty" yaml:"forward_stdin,omitempty" toml:"forward_stdin,omitempty"`
+	StdinEscape       string    `json:"stdin_escape,omitempty" yaml:"stdin_escape,omitempty" toml:"stdin_escape,omitempty"`
+	DebounceMs        int       `json:"debounce_ms,omitempty" yaml:"debounce_ms,omitempty" toml:"debounce_ms,omitzero"`
+	Color             *bool     `json:"color,omitempty" yaml:"color,omitempty" toml:"color,omitempty"`
+	commandOptionsDTO `yaml:",inline"`
+}
+
+// userConfigDTO 是用户级全局配置的数据传输对象
+type userConfigDTO struct {
+	Color             *bool     `json:"color,omitempty" yaml:"color,omitempty" toml:"color,omitempty"`
+	DebounceMs        int       `json:"debounce_ms,omitempty" yaml:"debounce_ms,omitempty" toml:"debounce_ms,omitzero"`
+	Backend           string    `json:"backend,omitempty" yaml:"backend,omitempty" toml:"backend,omitempty"`
+	PollIntervalMs    int       `json:"poll_interval_ms,omitempty" yaml:"poll_interval_ms,omitempty" toml:"poll_interval_ms,omitzero"`
+	Hooks             *hooksDTO `json:"hooks,omitempty" yaml:"hooks,omitempty" toml:"hooks,omitempty"`
+	commandOptionsDTO `yaml:",inline"`
+}
+
+// commandOptionsDTO 是命令执行选项的数据传输对象，可出现在顶层和规则中
+type commandOptionsDTO struct {
+	ChangedFilesStdin string         `json:"changed_files_stdin,omitempty" yaml:"changed_files_stdin,omitempty" toml:"changed_files_stdin,omitempty"`
+	OnBusy            string         `json:"on_busy,omitempty" yaml:"on_busy,omitempty" toml:"on_busy,omitempty"`
+	KillSignal        string         `json:"kill_signal,omitempty" yaml:"kill_signal,omitempty" toml:"kill_signal,omitempty"`
+	ReloadSignal      string         `json:"reload_signal,omitempty" yaml:"reload_signal,omitempty" toml:"reload_signal,omitempty"`
+	GracePeriodMs     int            `json:"grace_period_ms,omitempty" yaml:"grace_period_ms,omitempty" toml:"grace_period_ms,omitzero"`
+	TimeoutMs         int            `json:"timeout_ms,omitempty" yaml:"timeout_ms,omitempty" toml:"timeout_ms,omitzero"`
+	Supervise         *bool          `json:"supervise,omitempty" yaml:"supervise,omitempty" toml:"supervise,omitempty"`
+	MaxRestarts       int            `json:"max_restarts,omitempty" yaml:"max_restarts,omitempty" toml:"max_restarts,omitzero"`
+	RestartDelayMs    int            `json:"restart_delay_ms,omitempty" yaml:"restart_delay_ms,omitempty" toml:"restart_delay_ms,omitzero"`
+	PTY               *bool          `json:"pty,omitempty" yaml:"pty,omitempty" toml:"pty,omitempty"`
+	Ready             *readyProbeDTO `json:"ready,omitempty" yaml:"ready,omitempty" toml:"ready,omitempty"`
+	Output            *outputDTO     `json:"output,omitempty" yaml:"output,omitempty" toml:"output,omitempty"`
+}
+
+// readyProbeDTO 是就绪检查的数据传输对象
+type readyProbeDTO struct {
+	HTTP       string `json:"http,omitempty" yaml:"http,omitempty" toml:"http,omitempty"`
+	TCP        string `json:"tcp,omitempty" yaml:"tcp,omitempty" toml:"tcp,omitempty"`
+	Log        string `json:"log,omitempty" yaml:"log,omitempty" toml:"log,omitempty"`
+	TimeoutMs  int    `json:"timeout_ms,omitempty" yaml:"timeout_ms,omitempty" toml:"timeout_ms,omitzero"`
+	IntervalMs int    `json:"interval_ms,omitempty" yaml:"interval_ms,omitempty" toml:"interval_ms,omitzero"`
+}
+
+// outputDTO 是命令输出选项的数据传输对象
+type outputDTO struct {
+	Prefix     *bool `json:"prefix,omitempty" yaml:"prefix,omitempty" toml:"prefix,omitempty"`
+	Timestamps *bool `json:"timestamps,omitempty" yaml:"timestamps,omitempty" toml:"timestamps,omitempty"`
+	LogFile    *bool `json:"log_file,omitempty" yaml:"log_file,omitempty" toml:"log_file,omitempty"`
+	Lines      int   `json:"lines,omitempty" yaml:"lines,omitempty" toml:"lines,omitzero"`
+}
+
+// taskDTO 是流水线任务的数据传输对象
+type taskDTO struct {
+	Name      string   `json:"name" yaml:"name" toml:"name"`
+	Command   string   `json:"command" yaml:"command" toml:"command"`
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty" toml:"depends_on,omitempty"`
+	TimeoutMs int      `json:"timeout_ms,omitempty" yaml:"timeout_ms,omitempty" toml:"timeout_ms,omitzero"`
+}
+
+// hooksDTO 是生命周期钩子的数据传输对象
+type hooksDTO struct {
+	OnStart   string `json:"on_start,omitempty" yaml:"on_start,omitempty" toml:"on_start,omitempty"`
+	OnReady   string `json:"on_ready,omitempty" yaml:"on_ready,omitempty" toml:"on_ready,omitempty"`
+	OnSuccess string `json:"on_success,omitempty" yaml:"on_success,omitempty" toml:"on_success,omitempty"`
+	OnFailure string `json:"on_failure,omitempty" yaml:"on_failure,omitempty" toml:"on_failure,omitempty"`
+	OnStop    string `json:"on_stop,omitempty" yaml:"on_stop,omitempty" toml:"on_stop,omitempty"`
+}
+
+// ruleDTO 是路由规则的数据传输对象
+type ruleDTO struct {
+	Name              string   `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	Patterns          []string `json:"patterns" yaml:"patterns" toml:"patterns"`
+	Command           string   `json:"command" yaml:"command" toml:"command"`
+	commandOptionsDTO `yaml:",inline"`
+}
+
+// newCommandOptionsDTO 将命令执行选项转换为DTO
+func newCommandOptionsDTO(options entity.CommandOptions) commandOptionsDTO {
+	dto := commandOptionsDTO{
+		ChangedFilesStdin: string(options.FilesStdin),
+		OnBusy:            string(options.OnBusy),
+		KillSignal:        options.KillSignal,
+		ReloadSignal:      options.ReloadSignal,
+		GracePeriodMs:     int(options.GracePeriod / time.Millisecond),
+		TimeoutMs:         int(options.Timeout / time.Millisecond),
+		Supervise:         options.Supervise,
+		MaxRestarts:       options.MaxRestarts,
+		RestartDelayMs:    int(options.RestartDelay / time.Millisecond),
+		PTY:               options.PTY,
+	}
+	if options.Ready != nil {
+		dto.Ready = &readyProbeDTO{
+			HTTP:       options.Ready.HTTP,
+			TCP:        options.Ready.TCP,
+			Log:        options.Ready.LogPattern,
+			TimeoutMs:  int(options.Ready.Timeout / time.Millisecond),
+			IntervalMs: int(options.Ready.Interval / time.Millisecond),
+		}
+	}
+	if options.Output != (entity.OutputOptions{}) {
+		dto.Output = &outputDTO{
+			Prefix:     options.Output.Prefix,
+			Timestamps: options.Output.Timestamps,
+			LogFile:    options.Output.LogFile,
+			Lines:      options.Output.Lines,
+		}
+	}
+	return dto
+}
+
+// toEntity 将DTO转换为命令执行选项
+func (dto commandOptionsDTO) toEntity() entity.CommandOptions {
+	options := entity.CommandOptions{
+		FilesStdin:   entity.FilesStdinMode(dto.ChangedFilesStdin),
+		OnBusy:       entity.BusyPolicy(dto.OnBusy),
+		KillSignal:   dto.KillSignal,
+		ReloadSignal: dto.ReloadSignal,
+		GracePeriod:  time.Duration(dto.GracePeriodMs) * time.Millisecond,
+		Timeout:      time.Duration(dto.TimeoutMs) * time.Millisecond,
+		Supervise:    dto.Supervise,
+		MaxRestarts:  dto.MaxRestarts,
+		RestartDelay: time.Duration(dto.RestartDelayMs) * time.Millisecond,
+		PTY:          dto.PTY,
+	}
+	if dto.Ready != nil {
+		options.Ready = &entity.ReadyProbe{
+			HTTP:       dto.Ready.HTTP,
+			TCP:        dto.Ready.TCP,
+			LogPattern: dto.Ready.Log,
+			Timeout:    time.Duration(dto.Ready.TimeoutMs) * time.Millisecond,
+			Interval:   time.Duration(dto.Ready.IntervalMs) * time.Millisecond,
+		}
+	}
+	if dto.Output != nil {
+		options.Output = entity.OutputOptions{
+			Prefix:     dto.Output.Prefix,
+			Timestamps: dto.Output.Timestamps,
+			LogFile:    dto.Output.LogFile,
+			Lines:      dto.Output.Lines,
+		}
+	}
+	return options
+}
+
+// newConfigDTO 将领域实体转换为DTO
+func newConfigDTO(config *entity.WatchConfig) configDTO {
+	dto := configDTO{
+		WatchDir:     config.WatchDir,
+		FileTypes:    config.FileTypes,
+		ExcludePaths: config.ExcludePaths,
+		Include:      config.Include,
+		Exclude:      config.Exclude,
+		Command:      config.Command,
+	}
+	dto.commandOptionsDTO = newCommandOptionsDTO(config.CommandOptions)
+	dto.Tasks = newTaskDTOs(config.Tasks)
+	dto.Rules = newRuleDTOs(config.Rules)
+	dto.Hooks = newHooksDTO(config.Hooks)
+	dto.ForwardStdin = config.ForwardStdin
+	dto.StdinEscape = config.StdinEscape
+	dto.Backend = string(config.Backend)
+	dto.PollIntervalMs = int(config.PollInterval / time.Millisecond)
+	dto.DebounceMs = int(config.Debounce / time.Millisecond)
+	dto.Color = config.Color
+	if !config.UseGitignore {
+		dto.UseGitignore = &config.UseGitignore
+	}
+	if len(config.Profiles) > 0 {
+		dto.Profiles = make(map[string]profileDTO, len(config.Profiles))
+		for name, profile := range config.Profiles {
+			dto.Profiles[name] = newProfileDTO(profile)
+		}
+	}
+	return dto
+}
+
+// newProfileDTO 将配置方案转换为DTO
+func newProfileDTO(profile entity.Profile) profileDTO {
+	return profileDTO{
+		WatchDir:          profile.WatchDir,
+		FileTypes:         profile.FileTypes,
+		ExcludePaths:      profile.ExcludePaths,
+		Include:           profile.Include,
+		Exclude:           profile.Exclude,
+		Command:           profile.Command,
+		Tasks:             newTaskDTOs(profile.Tasks),
+		Rules:             newRuleDTOs(profile.Rules),
+		UseGitignore:      profile.UseGitignore,
+		Backend:           string(profile.Backend),
+		PollIntervalMs:    int(profile.PollInterval / time.Millisecond),
+		Hooks:             newHooksDTO(profile.Hooks),
+		ForwardStdin:      profile.ForwardStdin,
+		StdinEscape:       profile.StdinEscape,
+		DebounceMs:        int(profile.Debounce / time.Millisecond),
+		Color:             profile.Color,
+		commandOptionsDTO: newCommandOptionsDTO(profile.CommandOptions),
+	}
+}
+
+// newTaskDTOs 将流水线任务转换为DTO，保留空列表和 nil 的区别
+func newTaskDTOs(tasks []entity.Task) []taskDTO {
+	if tasks == nil {
+		return nil
+	}
+	dtos := make([]taskDTO, 0, len(tasks))
+	for _, task := range tasks {
+		dtos = append(dtos, taskDTO{
+			Name:      task.Name,
+			Command:   task.Command,
+			DependsOn: task.DependsOn,
+			TimeoutMs: int(task.Timeout / time.Millisecond),
+		})
+	}
+	return dtos
+}
+
+// newRuleDTOs 将路由规则转换为DTO，保留空列表和 nil 的区别
+func newRuleDTOs(rules []entity.Rule) []ruleDTO {
+	if rules == nil {
+		return nil
+	}
+	dtos := make([]ruleDTO, 0, len(rules))
+	for _, rule := range rules {
+		dtos = append(dtos, ruleDTO{
+			Name:     rule.Name,
+			Patterns: rule.Patterns,
+			Command:  rule.Command,
+
+			commandOptionsDTO: newCommandOptionsDTO(rule.CommandOptions),
+		})
+	}
+	return dtos
+}
+
+// newHooksDTO 将生命周期钩子转换为DTO，没有钩子时返回 nil
+func newHooksDTO(hooks entity.Hooks) *hooksDTO {
+	if hooks == (entity.Hooks{}) {
+		return nil
+	}
+	return &hooksDTO{
+		OnStart:   hooks.OnStart,
+		OnReady:   hooks.OnReady,
+		OnSuccess: hooks.OnSuccess,
+		OnFailure: hooks.OnFailure,
+		OnStop:    hooks.OnStop,
+	}
+}
+
+// toEntity 将DTO转换为经过校验的领域实体，监控目录和排除路径中的相对路径基于 baseDir
+func (dto *configDTO) toEntity(baseDir string) (*entity.WatchConfig, error) {
+	config, err := entity.NewWatchConfig(entity.ResolvePath(baseDir, dto.WatchDir), dto.FileTypes, dto.ExcludePaths, dto.Command)
+	if err != nil {
+		return nil, err
+	}
+	if baseDir != "" {
+		if config.BaseDir, err = filepath.Abs(baseDir); err != nil {
+			return nil, fmt.Errorf("获取绝对路径失败: %w", err)
+		}
+	}
+	config.Include = dto.Include
+	config.Exclude = dto.Exclude
+	config.Tasks = tasksToEntity(dto.Tasks)
+	config.Rules = rulesToEntity(dto.Rules)
+	config.CommandOptions = dto.commandOptionsDTO.toEntity()
+	config.Hooks = dto.Hooks.toEntity()
+	config.ForwardStdin = dto.ForwardStdin
+	config.StdinEscape = dto.StdinEscape
+	if dto.UseGitignore != nil {
+		config.UseGitignore = *dto.UseGitignore
+	}
+	config.Backend = entity.WatcherBackend(dto.Backend)
+	config.PollInterval = time.Duration(dto.PollIntervalMs) * time.Millisecond
+	config.Debounce = time.Duration(dto.DebounceMs) * time.Millisecond
+	config.Color = dto.Color
+	if len(dto.Profiles) > 0 {
+		config.Profiles = make(map[string]entity.Profile, len(dto.Profiles))
+		for name, profile := range dto.Profiles {
+			config.Profiles[name] = profile.toEntity()
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// toEntity 将DTO转换为配置方案，由 WatchConfig.Validate 校验
+func (dto profileDTO) toEntity() entity.Profile {
+	return entity.Profile{
+		WatchDir:       dto.WatchDir,
+		FileTypes:      dto.FileTypes,
+		ExcludePaths:   dto.ExcludePaths,
+		Include:        dto.Include,
+		Exclude:        dto.Exclude,
+		Command:        dto.Command,
+		Tasks:          tasksToEntity(dto.Tasks),
+		Rules:          rulesToEntity(dto.Rules),
+		CommandOptions: dto.commandOptionsDTO.toEntity(),
+		Hooks:          dto.Hooks.toEntity(),
+		ForwardStdin:   dto.ForwardStdin,
+		StdinEscape:    dto.StdinEscape,
+		UseGitignore:   dto.UseGitignore,
+		Backend:        entity.WatcherBackend(dto.Backend),
+		PollInterval:   time.Duration(dto.PollIntervalMs) * time.Millisecond,
+		Debounce:       time.Duration(dto.DebounceMs) * time.Millisecond,
+		Color:          dto.Color,
+	}
+}
+
+// toEntity 将DTO转换为经过校验的用户配置
+func (dto *userConfigDTO) toEntity() (*entity.UserConfig, error) {
+	config := &entity.UserConfig{
+		Color:          dto.Color,
+		Debounce:       time.Duration(dto.DebounceMs) * time.Millisecond,
+		Backend:        entity.WatcherBackend(dto.Backend),
+		PollInterval:   time.Duration(dto.PollIntervalMs) * time.Millisecond,
+		CommandOptions: dto.commandOptionsDTO.toEntity(),
+		Hooks:          dto.Hooks.toEntity(),
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// tasksToEntity 将DTO转换为流水线任务，保留空列表和 nil 的区别
+func tasksToEntity(dtos []taskDTO) []entity.Task {
+	if dtos == nil {
+		return nil
+	}
+	tasks := make([]entity.Task, 0, len(dtos))
+	for _, task := range dtos {
+		tasks = append(tasks, entity.Task{
+			Name:      task.Name,
+			Command:   task.Command,
+			DependsOn: task.DependsOn,
+			Timeout:   time.Duration(task.TimeoutMs) * time.Millisecond,
+		})
+	}
+	return tasks
+}
+
+// rulesToEntity 将DTO转换为路由规则，保留空列表和 nil 的区别
+func rulesToEntity(dtos []ruleDTO) []entity.Rule {
+	if dtos == nil {
+		return nil
+	}
+	rules := make([]entity.Rule, 0, len(dtos))
+	for _, rule := range dtos {
+		rules = append(rules, entity.Rule{
+			Name:     rule.Name,
+			Patterns: rule.Patterns,
+			Command:  rule.Command,
+
+			CommandOptions: rule.commandOptionsDTO.toEntity(),
+		})
+	}
+	return rules
+}
+
+// toEntity 将DTO转换为生命周期钩子，DTO 为 nil 时返回零值
+func (dto *hooksDTO) toEntity() entity.Hooks {
+	if dto == nil {
+		return entity.Hooks{}
+	}
+	return entity.Hooks{
+		OnStart:   dto.OnStart,
+		OnReady:   dto.OnReady,
+		OnSuccess: dto.OnSuccess,
+		OnFailure: dto.OnFailure,
+		OnStop:    dto.OnStop,
+	}
+}
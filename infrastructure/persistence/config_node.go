@@ -0,0 +1,634 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/watchs/domain/entity"
+)
+
+// nodeKind 表示配置节点的值类型
+type nodeKind int
+
+const (
+	nodeNull nodeKind = iota
+	nodeObject
+	nodeArray
+	nodeString
+	nodeInteger
+	nodeFloat
+	nodeBool
+	nodeTime
+)
+
+// String 返回值类型在问题描述中的名称
+func (k nodeKind) String() string {
+	switch k {
+	case nodeObject:
+		return "对象"
+	case nodeArray:
+		return "数组"
+	case nodeString:
+		return "字符串"
+	case nodeInteger:
+		return "整数"
+	case nodeFloat:
+		return "小数"
+	case nodeBool:
+		return "布尔值"
+	case nodeTime:
+		return "日期时间"
+	default:
+		return "null"
+	}
+}
+
+// configNode 是带有文件位置的配置树节点，检查配置时用来报告问题所在的行列
+// 三种配置格式都先解析为配置树，再按同样的规则检查
+type configNode struct {
+	kind   nodeKind
+	text   string
+	line   int
+	column int
+	// 对象的字段，保持文件中的顺序
+	fields []configField
+	// 数组的元素
+	items []*configNode
+	// YAML 中的 yes、no、on、off 等字符串，也可以作为布尔值
+	boolLike bool
+	// 整数、小数和布尔值节点解码后的值，分别为 int64、float64 和 bool
+	scalar any
+}
+
+// configField 是对象节点中的一个字段
+type configField struct {
+	key    string
+	line   int
+	column int
+	value  *configNode
+}
+
+// field 返回对象中指定键的值，键不存在或值为 null 时返回 nil
+func (n *configNode) field(key string) *configNode {
+	if n == nil || n.kind != nodeObject {
+		return nil
+	}
+	var value *configNode
+	for _, field := range n.fields {
+		if field.key == key {
+			value = field.value
+		}
+	}
+	if value == nil || value.kind == nodeNull {
+		return nil
+	}
+	return value
+}
+
+// elements 返回数组节点的元素，不是数组时返回 nil
+func (n *configNode) elements() []*configNode {
+	if n == nil || n.kind != nodeArray {
+		return nil
+	}
+	return n.items
+}
+
+// decode 将节点解码到 target，用于对配置树的一部分执行实体校验
+// 节点中有类型错误时返回错误，类型错误已由结构检查报告
+func (n *configNode) decode(target any) error {
+	data, err := json.Marshal(n.plain())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// plain 将节点转换为 map、切片和标量组成的值
+func (n *configNode) plain() any {
+	switch n.kind {
+	case nodeObject:
+		object := make(map[string]any, len(n.fields))
+		for _, field := range n.fields {
+			object[field.key] = field.value.plain()
+		}
+		return object
+	case nodeArray:
+		items := make([]any, 0, len(n.items))
+		for _, item := range n.items {
+			items = append(items, item.plain())
+		}
+		return items
+	case nodeString:
+		return n.text
+	case nodeNull:
+		return nil
+	default:
+		return n.scalar
+	}
+}
+
+// parseJSONNode 解析 JSON 配置文件，语法错误时返回带有位置的问题
+func parseJSONNode(data []byte) (*configNode, *entity.ConfigProblem) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	parser := &jsonNodeParser{data: data, decoder: decoder}
+
+	root, err := parser.parseValue()
+	if err == nil {
+		// 根节点之后不应再有其他内容
+		if _, err = parser.next(); err == io.EOF {
+			return root, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("配置文件末尾有多余的内容")
+		}
+	}
+	return nil, parser.problem(err)
+}
+
+// jsonNodeParser 逐个读取 JSON 记号构建配置树，并记录每个记号的起始位置
+type jsonNodeParser struct {
+	data    []byte
+	decoder *json.Decoder
+	// 最近读取的记号的起始偏移
+	offset int
+}
+
+// next 读取下一个记号
+func (p *jsonNodeParser) next() (json.Token, error) {
+	p.offset = skipJSONSeparators(p.data, int(p.decoder.InputOffset()))
+	return p.decoder.Token()
+}
+
+// parseValue 读取一个完整的值
+func (p *jsonNodeParser) parseValue() (*configNode, error) {
+	token, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &configNode{}
+	node.line, node.column = textPosition(p.data, p.offset)
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			node.kind = nodeObject
+			err = p.parseObject(node)
+		} else {
+			node.kind = nodeArray
+			err = p.parseArray(node)
+		}
+	case string:
+		node.kind = nodeString
+		node.text = value
+	case json.Number:
+		node.kind = nodeFloat
+		node.scalar, _ = value.Float64()
+		if number, err := value.Int64(); err == nil {
+			node.kind = nodeInteger
+			node.scalar = number
+		}
+	case bool:
+		node.kind = nodeBool
+		node.scalar = value
+	case nil:
+		node.kind = nodeNull
+	}
+	return node, err
+}
+
+// parseObject 读取对象的字段直到对象结束
+func (p *jsonNodeParser) parseObject(node *configNode) error {
+	for p.decoder.More() {
+		token, err := p.next()
+		if err != nil {
+			return err
+		}
+		field := configField{}
+		field.key, _ = token.(string)
+		field.line, field.column = textPosition(p.data, p.offset)
+		if field.value, err = p.parseValue(); err != nil {
+			return err
+		}
+		node.fields = append(node.fields, field)
+	}
+	_, err := p.next()
+	return err
+}
+
+// parseArray 读取数组的元素直到数组结束
+func (p *jsonNodeParser) parseArray(node *configNode) error {
+	for p.decoder.More() {
+		item, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		node.items = append(node.items, item)
+	}
+	_, err := p.next()
+	return err
+}
+
+// problem 将解析错误转换为带有位置的问题
+func (p *jsonNodeParser) problem(err error) *entity.ConfigProblem {
+	offset := p.offset
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = int(syntaxErr.Offset)
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		offset = len(p.data)
+		err = fmt.Errorf("配置文件意外结束")
+	}
+	line, column := textPosition(p.data, offset)
+	return &entity.ConfigProblem{Line: line, Column: column, Message: fmt.Sprintf("语法错误: %v", err)}
+}
+
+// skipJSONSeparators 跳过记号之间的空白、逗号和冒号，返回下一个记号的起始偏移
+func skipJSONSeparators(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// textPosition 将字节偏移转换为从 1 开始的行号和列号，列号按字符计算
+func textPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return bytes.Count(before, []byte("\n")) + 1, utf8.RuneCount(before[lineStart:]) + 1
+}
+
+// yamlErrorLine 匹配 YAML 错误信息中的行号
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// parseYAMLNode 解析 YAML 配置文件，语法错误时返回带有位置的问题
+func parseYAMLNode(data []byte) (*configNode, *entity.ConfigProblem) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		problem := &entity.ConfigProblem{Message: fmt.Sprintf("语法错误: %v", err)}
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			problem.Line, _ = strconv.Atoi(match[1])
+			problem.Message = fmt.Sprintf("语法错误: %s", match[2])
+		}
+		return nil, problem
+	}
+
+	// 空文件按空对象处理
+	if len(document.Content) == 0 {
+		return &configNode{kind: nodeObject, line: 1, column: 1}, nil
+	}
+	return convertYAMLNode(document.Content[0]), nil
+}
+
+// yamlBoolStrings 是 YAML 1.1 中表示布尔值的字符串，解码到布尔类型的配置项时可以使用
+var yamlBoolStrings = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true,
+}
+
+// convertYAMLNode 将 YAML 节点转换为配置树节点
+func convertYAMLNode(source *yaml.Node) *configNode {
+	if source.Kind == yaml.AliasNode && source.Alias != nil {
+		node := convertYAMLNode(source.Alias)
+		node.line, node.column = source.Line, source.Column
+		return node
+	}
+
+	node := &configNode{line: source.Line, column: source.Column}
+	switch source.Kind {
+	case yaml.MappingNode:
+		node.kind = nodeObject
+		for i := 0; i+1 < len(source.Content); i += 2 {
+			key, value := source.Content[i], source.Content[i+1]
+			node.fields = append(node.fields, configField{
+				key:    key.Value,
+				line:   key.Line,
+				column: key.Column,
+				value:  convertYAMLNode(value),
+			})
+		}
+	case yaml.SequenceNode:
+		node.kind = nodeArray
+		for _, item := range source.Content {
+			node.items = append(node.items, convertYAMLNode(item))
+		}
+	case yaml.ScalarNode:
+		switch source.ShortTag() {
+		case "!!int":
+			node.kind = nodeInteger
+			var number int64
+			if source.Decode(&number) == nil {
+				node.scalar = number
+			}
+		case "!!float":
+			node.kind = nodeFloat
+			var number float64
+			if source.Decode(&number) == nil {
+				node.scalar = number
+			}
+		case "!!bool":
+			node.kind = nodeBool
+			var flag bool
+			if source.Decode(&flag) == nil {
+				node.scalar = flag
+			}
+		case "!!null":
+			node.kind = nodeNull
+		case "!!timestamp":
+			node.kind = nodeTime
+		default:
+			node.kind = nodeString
+			node.text = source.Value
+			node.boolLike = yamlBoolStrings[source.Value]
+		}
+	}
+	return node
+}
+
+// parseTOMLNode 解析 TOML 配置文件，语法错误时返回带有位置的问题
+// TOML 解析器不提供键和值的位置，配置项的行列由 tomlKeyPositions 按行扫描得到
+func parseTOMLNode(data []byte) (*configNode, *entity.ConfigProblem) {
+	var values map[string]any
+	if _, err := toml.Decode(string(data), &values); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &entity.ConfigProblem{
+				Line:    parseErr.Position.Line,
+				Column:  parseErr.Position.Col,
+				Message: fmt.Sprintf("语法错误: %s", parseErr.Message),
+			}
+		}
+		return nil, &entity.ConfigProblem{Message: fmt.Sprintf("语法错误: %v", err)}
+	}
+
+	positions := tomlKeyPositions(data)
+	root := convertTOMLValue(values, "", positions)
+	root.line, root.column = 1, 1
+	return root, nil
+}
+
+// convertTOMLValue 将 TOML 解析出的值转换为配置树节点
+func convertTOMLValue(value any, path string, positions tomlPositions) *configNode {
+	node := &configNode{}
+	node.line, node.column = lookupPosition(positions.values, path)
+
+	switch value := value.(type) {
+	case map[string]any:
+		node.kind = nodeObject
+		for key, fieldValue := range value {
+			fieldPath := joinConfigPath(path, key)
+			field := configField{key: key, value: convertTOMLValue(fieldValue, fieldPath, positions)}
+			field.line, field.column = lookupPosition(positions.keys, fieldPath)
+			node.fields = append(node.fields, field)
+		}
+		// map 没有顺序，按字段在文件中的位置排列
+		sort.Slice(node.fields, func(i, j int) bool {
+			a, b := node.fields[i], node.fields[j]
+			if a.line != b.line {
+				return a.line < b.line
+			}
+			return a.key < b.key
+		})
+	case []map[string]any:
+		node.kind = nodeArray
+		for i, item := range value {
+			node.items = append(node.items, convertTOMLValue(item, fmt.Sprintf("%s[%d]", path, i), positions))
+		}
+	case []any:
+		node.kind = nodeArray
+		for i, item := range value {
+			node.items = append(node.items, convertTOMLValue(item, fmt.Sprintf("%s[%d]", path, i), positions))
+		}
+	case string:
+		node.kind = nodeString
+		node.text = value
+	case int64:
+		node.kind = nodeInteger
+		node.scalar = value
+	case float64:
+		node.kind = nodeFloat
+		node.scalar = value
+	case bool:
+		node.kind = nodeBool
+		node.scalar = value
+	default:
+		node.kind = nodeTime
+	}
+	return node
+}
+
+// textPos 表示文件中的行号和列号
+type textPos struct {
+	line   int
+	column int
+}
+
+// tomlTableHeader 匹配 [table] 和 [[array]] 表头
+var tomlTableHeader = regexp.MustCompile(`^(\[\[?)\s*([^\]]+?)\s*\]\]?`)
+
+// tomlPositions 记录 TOML 文件中配置项的键和值所在的位置，路径的格式与问题中的路径相同
+type tomlPositions struct {
+	keys   map[string]textPos
+	values map[string]textPos
+}
+
+// tomlKeyPositions 按行扫描 TOML 文件，记录表头、键和值所在的位置，如 rules[1].command
+// 同一行和多行数组的元素也记录位置；多行字符串的内容会被跳过
+func tomlKeyPositions(data []byte) tomlPositions {
+	positions := tomlPositions{keys: make(map[string]textPos), values: make(map[string]textPos)}
+	arrays := make(map[string]int)
+	table := ""
+	var array *tomlArray
+	multiline := ""
+
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		keyStart := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		// 跳过多行字符串的后续行，继续扫描多行数组的元素
+		if multiline != "" {
+			if strings.Count(line, multiline)%2 == 1 {
+				multiline = ""
+			}
+			continue
+		}
+		if array != nil {
+			if array.scan(line, 0, i+1, positions.values) {
+				array = nil
+			}
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if match := tomlTableHeader.FindStringSubmatch(trimmed); match != nil {
+			table = resolveTOMLTable(match[2], arrays)
+			pos := textPos{line: i + 1, column: textColumn(line, keyStart)}
+			if match[1] == "[[" {
+				arrays[table]++
+				if arrays[table] == 1 {
+					positions.keys[table], positions.values[table] = pos, pos
+				}
+				table = fmt.Sprintf("%s[%d]", table, arrays[table]-1)
+			}
+			positions.keys[table], positions.values[table] = pos, pos
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			continue
+		}
+		key := joinConfigPath(table, tomlKeyPath(line[keyStart:eq]))
+		positions.keys[key] = textPos{line: i + 1, column: textColumn(line, keyStart)}
+
+		valueStart := eq + 1 + len(line[eq+1:]) - len(strings.TrimLeft(line[eq+1:], " \t"))
+		positions.values[key] = textPos{line: i + 1, column: textColumn(line, valueStart)}
+
+		value := line[valueStart:]
+		for _, quote := range []string{`"""`, `'''`} {
+			if strings.Count(value, quote)%2 == 1 {
+				multiline = quote
+			}
+		}
+		if multiline == "" && (strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{")) {
+			array = &tomlArray{path: key, items: strings.HasPrefix(value, "[")}
+			if array.scan(line, valueStart, i+1, positions.values) {
+				array = nil
+			}
+		}
+	}
+	return positions
+}
+
+// tomlArray 是正在扫描的数组或内联表，可能跨越多行
+type tomlArray struct {
+	path string
+	// 是否记录元素的位置，内联表不记录
+	items bool
+	// 未闭合的方括号和花括号数量
+	depth int
+	// 当前元素的下标
+	index int
+	// 下一个非空白字符是否为新元素的开始
+	expect bool
+}
+
+// scan 扫描一行中从 start 开始的内容，记录元素的位置，返回数组是否已经结束
+// 忽略字符串和注释中的括号
+func (a *tomlArray) scan(line string, start, lineNo int, values map[string]textPos) bool {
+	quote := rune(0)
+	escaped := false
+	for offset, r := range line[start:] {
+		offset += start
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\' && quote == '"':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+		if r == '#' {
+			return false
+		}
+		if r == ' ' || r == '\t' || r == '\r' {
+			continue
+		}
+
+		if a.items && a.depth == 1 && a.expect && r != ']' && r != ',' {
+			values[fmt.Sprintf("%s[%d]", a.path, a.index)] = textPos{line: lineNo, column: textColumn(line, offset)}
+			a.expect = false
+		}
+		switch r {
+		case '"', '\'':
+			quote = r
+		case '[', '{':
+			a.depth++
+			if a.depth == 1 {
+				a.expect = true
+			}
+		case ']', '}':
+			a.depth--
+			if a.depth == 0 {
+				return true
+			}
+		case ',':
+			if a.depth == 1 {
+				a.index++
+				a.expect = true
+			}
+		}
+	}
+	return false
+}
+
+// textColumn 返回一行中字节偏移处的列号，按字符计数
+func textColumn(line string, offset int) int {
+	return utf8.RuneCountInString(line[:offset]) + 1
+}
+
+// resolveTOMLTable 将表头中的名称转换为路径，上级名称引用的数组表使用其最后一个元素
+func resolveTOMLTable(name string, arrays map[string]int) string {
+	parts := strings.Split(tomlKeyPath(name), ".")
+	path := ""
+	for i, part := range parts {
+		path = joinConfigPath(path, part)
+		if count := arrays[path]; count > 0 && i < len(parts)-1 {
+			path = fmt.Sprintf("%s[%d]", path, count-1)
+		}
+	}
+	return path
+}
+
+// tomlKeyPath 去掉键两侧的空白和引号，点分隔的键转换为路径
+func tomlKeyPath(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// lookupPosition 返回路径对应的位置，找不到时依次使用上级配置项的位置
+func lookupPosition(positions map[string]textPos, path string) (int, int) {
+	for path != "" {
+		if pos, ok := positions[path]; ok {
+			return pos.line, pos.column
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			break
+		}
+		path = path[:cut]
+	}
+	return 0, 0
+}
+
+// joinConfigPath 拼接配置项路径
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
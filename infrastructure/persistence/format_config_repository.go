@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/domain/repository"
+)
+
+// DefaultConfigFile 目录中没有配置文件时使用的默认配置文件名
+const DefaultConfigFile = "watchs.json"
+
+// configFileNames 按优先级排列的配置文件名
+var configFileNames = []string{DefaultConfigFile, "watchs.yaml", "watchs.yml", "watchs.toml"}
+
+// userConfigFileNames 按优先级排列的用户配置文件名，没有扩展名的 config 按YAML（兼容JSON）解析
+var userConfigFileNames = []string{"config", "config.json", "config.yaml", "config.yml", "config.toml"}
+
+// FormatConfigRepository 根据文件扩展名选择 JSON、YAML 或 TOML 配置仓储
+// .yaml 和 .yml 使用YAML，.toml 使用TOML，其余扩展名使用JSON
+type FormatConfigRepository struct {
+	json repository.ConfigRepository
+	yaml repository.ConfigRepository
+	toml repository.ConfigRepository
+}
+
+// NewFormatConfigRepository 创建一个新的按扩展名选择格式的配置仓储
+func NewFormatConfigRepository() *FormatConfigRepository {
+	return &FormatConfigRepository{
+		json: NewJsonConfigRepository(),
+		yaml: NewYamlConfigRepository(),
+		toml: NewTomlConfigRepository(),
+	}
+}
+
+// LoadConfig 按文件扩展名对应的格式加载配置
+func (r *FormatConfigRepository) LoadConfig(path string) (*entity.WatchConfig, error) {
+	return r.repositoryFor(path).LoadConfig(path)
+}
+
+// SaveConfig 按文件扩展名对应的格式保存配置
+func (r *FormatConfigRepository) SaveConfig(config *entity.WatchConfig, path string) error {
+	return r.repositoryFor(path).SaveConfig(config, path)
+}
+
+// CheckConfig 按文件扩展名对应的格式检查配置文件
+func (r *FormatConfigRepository) CheckConfig(path string) ([]entity.ConfigProblem, error) {
+	return r.repositoryFor(path).CheckConfig(path)
+}
+
+// LoadUserConfig 按文件扩展名对应的格式加载用户配置，没有扩展名时按YAML解析
+func (r *FormatConfigRepository) LoadUserConfig(path string) (*entity.UserConfig, error) {
+	if filepath.Ext(path) == "" {
+		return r.yaml.LoadUserConfig(path)
+	}
+	return r.repositoryFor(path).LoadUserConfig(path)
+}
+
+// repositoryFor 返回文件扩展名对应的配置仓储
+func (r *FormatConfigRepository) repositoryFor(path string) repository.ConfigRepository {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return r.yaml
+	case ".toml":
+		return r.toml
+	default:
+		return r.json
+	}
+}
+
+// DiscoverConfigFile 在目录中按 watchs.json、watchs.yaml、watchs.yml、watchs.toml 的顺序查找配置文件
+// 目录位于 Git 仓库中时再逐级向上查找到仓库根目录为止，都不存在时返回目录中的 watchs.json
+func DiscoverConfigFile(dir string) string {
+	if path := findFile(dir, configFileNames); path != "" {
+		return path
+	}
+
+	// 不在仓库中时不向上查找，避免用到主目录或 /tmp 中无关的配置文件
+	root := repositoryRoot(dir)
+	current, err := filepath.Abs(dir)
+	if root == "" || err != nil {
+		return filepath.Join(dir, DefaultConfigFile)
+	}
+	for current != root {
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+		if path := findFile(current, configFileNames); path != "" {
+			return path
+		}
+	}
+	return filepath.Join(dir, DefaultConfigFile)
+}
+
+// repositoryRoot 返回目录所在的 Git 仓库根目录（包含 .git 的目录）的绝对路径
+// 到达用户主目录或文件系统根目录仍未找到时返回空字符串
+func repositoryRoot(dir string) string {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	for {
+		if home != "" && current == filepath.Clean(home) {
+			return ""
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return ""
+		}
+		current = parent
+	}
+}
+
+// FindUserConfigFile 查找用户级的全局配置文件 $XDG_CONFIG_HOME/watchs/config
+// 未设置 XDG_CONFIG_HOME 时使用系统的用户配置目录，文件不存在时返回空字符串
+func FindUserConfigFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		userDir, err := os.UserConfigDir()
+		if err != nil {
+			return ""
+		}
+		dir = userDir
+	}
+	return findFile(filepath.Join(dir, "watchs"), userConfigFileNames)
+}
+
+// findFile 在目录中按顺序查找第一个存在的文件，都不存在时返回空字符串
+func findFile(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
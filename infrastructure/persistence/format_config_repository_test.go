@@ -0,0 +1,247 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// makeTree 在 root 下创建目录和文件，以 / 结尾的路径为目录
+func makeTree(t *testing.T, root string, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if path[len(path)-1] == '/' {
+			if err := os.MkdirAll(full, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// chdir 切换工作目录，测试结束后恢复
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+// discover 调用 DiscoverConfigFile 并返回绝对路径，超时未返回时测试失败
+func discover(t *testing.T, dir string) string {
+	t.Helper()
+	done := make(chan string, 1)
+	go func() {
+		done <- DiscoverConfigFile(dir)
+	}()
+	select {
+	case path := <-done:
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return absPath
+	case <-time.After(5 * time.Second):
+		t.Fatalf("DiscoverConfigFile(%q) did not return", dir)
+		return ""
+	}
+}
+
+// TestDiscoverConfigFile 在当前目录和仓库内的上级目录中查找配置文件
+func TestDiscoverConfigFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		// 工作目录，相对于临时目录
+		cwd string
+		// 期望的配置文件，相对于临时目录
+		want string
+	}{
+		{
+			name:  "repository root without config",
+			files: []string{"repo/.git/"},
+			cwd:   "repo",
+			want:  "repo/watchs.json",
+		},
+		{
+			name:  "repository root with config",
+			files: []string{"repo/.git/", "repo/watchs.yaml"},
+			cwd:   "repo",
+			want:  "repo/watchs.yaml",
+		},
+		{
+			name:  "priority inside directory",
+			files: []string{"repo/.git/", "repo/watchs.toml", "repo/watchs.json"},
+			cwd:   "repo",
+			want:  "repo/watchs.json",
+		},
+		{
+			name:  "subdirectory finds repository root config",
+			files: []string{"repo/.git/", "repo/watchs.toml", "repo/a/b/"},
+			cwd:   "repo/a/b",
+			want:  "repo/watchs.toml",
+		},
+		{
+			name:  "nearest config wins",
+			files: []string{"repo/.git/", "repo/watchs.yaml", "repo/a/watchs.json", "repo/a/b/"},
+			cwd:   "repo/a/b",
+			want:  "repo/a/watchs.json",
+		},
+		{
+			name:  "subdirectory does not search above repository root",
+			files: []string{"watchs.yaml", "repo/.git/", "repo/a/"},
+			cwd:   "repo/a",
+			want:  "repo/a/watchs.json",
+		},
+		{
+			name:  "outside repository",
+			files: []string{"watchs.yaml", "plain/a/"},
+			cwd:   "plain/a",
+			want:  "plain/a/watchs.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("HOME", root)
+			makeTree(t, root, tt.files...)
+			chdir(t, filepath.Join(root, filepath.FromSlash(tt.cwd)))
+
+			want := filepath.Join(root, filepath.FromSlash(tt.want))
+			if got := discover(t, "."); got != want {
+				t.Errorf("DiscoverConfigFile(\".\") = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// TestRepositoryRoot 向上查找包含 .git 的目录，到达用户主目录时停止
+func TestRepositoryRoot(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		// 用户主目录，相对于临时目录，为空时使用临时目录
+		home string
+		dir  string
+		// 期望的仓库根目录，相对于临时目录，为空表示没有找到
+		want string
+	}{
+		{
+			name:  "repository root",
+			files: []string{"repo/.git/"},
+			dir:   "repo",
+			want:  "repo",
+		},
+		{
+			name:  "nested directory",
+			files: []string{"repo/.git/", "repo/a/b/"},
+			dir:   "repo/a/b",
+			want:  "repo",
+		},
+		{
+			name:  "git file of a worktree",
+			files: []string{"repo/.git", "repo/a/"},
+			dir:   "repo/a",
+			want:  "repo",
+		},
+		{
+			name:  "no repository",
+			files: []string{"plain/a/"},
+			dir:   "plain/a",
+		},
+		{
+			name:  "stops at home directory",
+			files: []string{".git/", "home/project/"},
+			home:  "home",
+			dir:   "home/project",
+		},
+		{
+			name:  "repository inside home directory",
+			files: []string{"home/project/.git/", "home/project/src/"},
+			home:  "home",
+			dir:   "home/project/src",
+			want:  "home/project",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("HOME", filepath.Join(root, filepath.FromSlash(tt.home)))
+			makeTree(t, root, tt.files...)
+
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(root, filepath.FromSlash(tt.want))
+			}
+			if got := repositoryRoot(filepath.Join(root, filepath.FromSlash(tt.dir))); got != want {
+				t.Errorf("repositoryRoot(%q) = %q, want %q", tt.dir, got, want)
+			}
+		})
+	}
+}
+
+// TestFindUserConfigFile 在 $XDG_CONFIG_HOME/watchs 中按优先级查找用户配置文件
+func TestFindUserConfigFile(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		// 期望的配置文件，相对于 $XDG_CONFIG_HOME，为空表示没有找到
+		want string
+	}{
+		{
+			name: "no config",
+		},
+		{
+			name:  "config without extension",
+			files: []string{"watchs/config", "watchs/config.toml"},
+			want:  "watchs/config",
+		},
+		{
+			name:  "yaml config",
+			files: []string{"watchs/config.yaml", "watchs/config.toml"},
+			want:  "watchs/config.yaml",
+		},
+		{
+			name:  "directory is not a config file",
+			files: []string{"watchs/config/", "watchs/config.toml"},
+			want:  "watchs/config.toml",
+		},
+		{
+			name:  "project config names are ignored",
+			files: []string{"watchs/watchs.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			t.Setenv("XDG_CONFIG_HOME", root)
+			makeTree(t, root, tt.files...)
+
+			want := ""
+			if tt.want != "" {
+				want = filepath.Join(root, filepath.FromSlash(tt.want))
+			}
+			if got := FindUserConfigFile(); got != want {
+				t.Errorf("FindUserConfigFile() = %q, want %q", got, want)
+			}
+		})
+	}
+}
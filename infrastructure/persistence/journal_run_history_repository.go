@@ -0,0 +1,138 @@
+package persistence
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/watchs/domain/entity"
+)
+
+const (
+	// journalFileName 执行历史日志的文件名，每行一条 JSON 记录
+	journalFileName = "runs.jsonl"
+	// defaultJournalMaxSize 日志文件超过该大小后轮转
+	defaultJournalMaxSize = 1 << 20
+	// defaultJournalBackups 轮转后保留的旧日志文件数量
+	defaultJournalBackups = 3
+	// maxJournalLine 读取日志时允许的最大行长度
+	maxJournalLine = 1 << 20
+)
+
+// JournalRunHistoryRepository 是基于 JSON Lines 日志文件的执行历史仓储实现
+// 日志文件超过大小上限时轮转为 runs.jsonl.1、runs.jsonl.2……，只保留最近的几个
+type JournalRunHistoryRepository struct {
+	maxSize int64
+	backups int
+	mu      sync.Mutex
+}
+
+// NewJournalRunHistoryRepository 创建一个新的执行历史仓储
+func NewJournalRunHistoryRepository() *JournalRunHistoryRepository {
+	return &JournalRunHistoryRepository{
+		maxSize: defaultJournalMaxSize,
+		backups: defaultJournalBackups,
+	}
+}
+
+// Append 将一次执行结果追加到日志文件，必要时先轮转
+func (r *JournalRunHistoryRepository) Append(dir string, result *entity.RunResult) error {
+	line, err := json.Marshal(newRunRecordDTO(result))
+	if err != nil {
+		return fmt.Errorf("序列化执行记录失败: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建执行历史目录失败: %w", err)
+	}
+
+	path := filepath.Join(dir, journalFileName)
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > r.maxSize {
+		if err := r.rotate(path); err != nil {
+			return fmt.Errorf("轮转执行历史失败: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("打开执行历史失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("写入执行历史失败: %w", err)
+	}
+	return nil
+}
+
+// List 从最旧的轮转文件开始读取所有执行记录，无法解析的行会被跳过
+func (r *JournalRunHistoryRepository) List(dir string) ([]*entity.RunResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := filepath.Join(dir, journalFileName)
+	var results []*entity.RunResult
+	for i := r.backups; i >= 0; i-- {
+		records, err := readJournal(backupPath(path, i))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, records...)
+	}
+	return results, nil
+}
+
+// rotate 将日志文件依次后移一位，丢弃最旧的文件
+func (r *JournalRunHistoryRepository) rotate(path string) error {
+	for i := r.backups; i > 0; i-- {
+		err := os.Rename(backupPath(path, i-1), backupPath(path, i))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupPath 返回第 index 个轮转文件的路径，0 表示当前日志文件
+func backupPath(path string, index int) string {
+	if index == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.%d", path, index)
+}
+
+// readJournal 读取单个日志文件中的执行记录，文件不存在时返回空列表
+func readJournal(path string) ([]*entity.RunResult, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取执行历史失败: %w", err)
+	}
+	defer file.Close()
+
+	var results []*entity.RunResult
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxJournalLine)
+	for scanner.Scan() {
+		var dto runRecordDTO
+		// 进程异常退出时最后一行可能不完整
+		if err := json.Unmarshal(scanner.Bytes(), &dto); err != nil {
+			continue
+		}
+		results = append(results, dto.toEntity())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取执行历史失败: %w", err)
+	}
+	return results, nil
+}
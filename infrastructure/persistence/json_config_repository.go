@@ -4,18 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/watchs/domain/entity"
 )
 
-// configDTO 是配置的数据传输对象
-type configDTO struct {
-	WatchDir     string   `json:"watch_dir"`
-	FileTypes    []string `json:"file_types"`
-	ExcludePaths []string `json:"exclude_paths"`
-	Command      string   `json:"command"`
-}
-
 // JsonConfigRepository 是基于JSON文件的配置仓储实现
 type JsonConfigRepository struct{}
 
@@ -30,30 +23,55 @@ func (r *JsonConfigRepository) LoadConfig(path string) (*entity.WatchConfig, err
 	if err != nil {
 		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
+	return r.parse(data, filepath.Dir(path))
+}
+
+// CheckConfig 检查JSON配置文件，一次返回其中的所有问题
+func (r *JsonConfigRepository) CheckConfig(path string) ([]entity.ConfigProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
 
+	root, problem := parseJSONNode(data)
+	if problem != nil {
+		return []entity.ConfigProblem{*problem}, nil
+	}
+	baseDir := filepath.Dir(path)
+	return checkConfigTree(root, baseDir, func() (*entity.WatchConfig, error) {
+		return r.parse(data, baseDir)
+	}), nil
+}
+
+// parse 将JSON内容解析为领域实体，配置中的相对路径基于 baseDir
+func (r *JsonConfigRepository) parse(data []byte, baseDir string) (*entity.WatchConfig, error) {
 	var dto configDTO
 	if err := json.Unmarshal(data, &dto); err != nil {
 		return nil, fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
 	// 转换为领域实体
-	config, err := entity.NewWatchConfig(dto.WatchDir, dto.FileTypes, dto.ExcludePaths, dto.Command)
+	return dto.toEntity(baseDir)
+}
+
+// LoadUserConfig 从JSON文件加载用户级的全局配置
+func (r *JsonConfigRepository) LoadUserConfig(path string) (*entity.UserConfig, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
 	}
 
-	return config, nil
+	var dto userConfigDTO
+	if err := json.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	return dto.toEntity()
 }
 
 // SaveConfig 保存配置到JSON文件
 func (r *JsonConfigRepository) SaveConfig(config *entity.WatchConfig, path string) error {
 	// 转换为DTO
-	dto := configDTO{
-		WatchDir:     config.WatchDir,
-		FileTypes:    config.FileTypes,
-		ExcludePaths: config.ExcludePaths,
-		Command:      config.Command,
-	}
+	dto := newConfigDTO(config)
 
 	data, err := json.MarshalIndent(dto, "", "  ")
 	if err != nil {
@@ -0,0 +1,63 @@
+package persistence
+
+import (
+	"errors"
+	"time"
+
+	"github.com/watchs/domain/entity"
+)
+
+// runRecordDTO 是执行历史中一条记录的数据传输对象
+type runRecordDTO struct {
+	ID         string    `json:"id"`
+	Command    string    `json:"command"`
+	Files      []string  `json:"files,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	DurationMs int64     `json:"duration_ms"`
+	ExitCode   int       `json:"exit_code"`
+	Signal     string    `json:"signal,omitempty"`
+	Terminated bool      `json:"terminated,omitempty"`
+	TimedOut   bool      `json:"timed_out,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Output     string    `json:"output,omitempty"`
+}
+
+// newRunRecordDTO 将执行结果转换为DTO
+func newRunRecordDTO(result *entity.RunResult) runRecordDTO {
+	dto := runRecordDTO{
+		ID:         result.RunID,
+		Command:    result.Command,
+		Files:      result.Files,
+		StartTime:  result.StartTime,
+		DurationMs: result.Duration.Milliseconds(),
+		ExitCode:   result.ExitCode,
+		Signal:     result.Signal,
+		Terminated: result.Terminated,
+		TimedOut:   result.TimedOut,
+		Output:     result.Output,
+	}
+	if result.Err != nil {
+		dto.Error = result.Err.Error()
+	}
+	return dto
+}
+
+// toEntity 将DTO转换为执行结果
+func (dto runRecordDTO) toEntity() *entity.RunResult {
+	result := &entity.RunResult{
+		RunID:      dto.ID,
+		Command:    dto.Command,
+		Files:      dto.Files,
+		StartTime:  dto.StartTime,
+		Duration:   time.Duration(dto.DurationMs) * time.Millisecond,
+		ExitCode:   dto.ExitCode,
+		Signal:     dto.Signal,
+		Terminated: dto.Terminated,
+		TimedOut:   dto.TimedOut,
+		Output:     dto.Output,
+	}
+	if dto.Error != "" {
+		result.Err = errors.New(dto.Error)
+	}
+	return result
+}
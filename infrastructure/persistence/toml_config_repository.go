@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/watchs/domain/entity"
+)
+
+// TomlConfigRepository 是基于TOML文件的配置仓储实现，配置文件中可以使用注释
+type TomlConfigRepository struct{}
+
+// NewTomlConfigRepository 创建一个新的TOML配置仓储
+func NewTomlConfigRepository() *TomlConfigRepository {
+	return &TomlConfigRepository{}
+}
+
+// LoadConfig 从TOML文件加载配置
+func (r *TomlConfigRepository) LoadConfig(path string) (*entity.WatchConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	return r.parse(data, filepath.Dir(path))
+}
+
+// CheckConfig 检查TOML配置文件，一次返回其中的所有问题
+func (r *TomlConfigRepository) CheckConfig(path string) ([]entity.ConfigProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	root, problem := parseTOMLNode(data)
+	if problem != nil {
+		return []entity.ConfigProblem{*problem}, nil
+	}
+	baseDir := filepath.Dir(path)
+	return checkConfigTree(root, baseDir, func() (*entity.WatchConfig, error) {
+		return r.parse(data, baseDir)
+	}), nil
+}
+
+// parse 将TOML内容解析为领域实体，配置中的相对路径基于 baseDir
+func (r *TomlConfigRepository) parse(data []byte, baseDir string) (*entity.WatchConfig, error) {
+	var dto configDTO
+	if err := toml.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	// 转换为领域实体
+	return dto.toEntity(baseDir)
+}
+
+// LoadUserConfig 从TOML文件加载用户级的全局配置
+func (r *TomlConfigRepository) LoadUserConfig(path string) (*entity.UserConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var dto userConfigDTO
+	if err := toml.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	return dto.toEntity()
+}
+
+// SaveConfig 保存配置到TOML文件
+func (r *TomlConfigRepository) SaveConfig(config *entity.WatchConfig, path string) error {
+	// 转换为DTO
+	dto := newConfigDTO(config)
+
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(dto); err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package persistence
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/watchs/domain/entity"
+)
+
+// YamlConfigRepository 是基于YAML文件的配置仓储实现，配置文件中可以使用注释
+type YamlConfigRepository struct{}
+
+// NewYamlConfigRepository 创建一个新的YAML配置仓储
+func NewYamlConfigRepository() *YamlConfigRepository {
+	return &YamlConfigRepository{}
+}
+
+// LoadConfig 从YAML文件加载配置
+func (r *YamlConfigRepository) LoadConfig(path string) (*entity.WatchConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	return r.parse(data, filepath.Dir(path))
+}
+
+// CheckConfig 检查YAML配置文件，一次返回其中的所有问题
+func (r *YamlConfigRepository) CheckConfig(path string) ([]entity.ConfigProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	root, problem := parseYAMLNode(data)
+	if problem != nil {
+		return []entity.ConfigProblem{*problem}, nil
+	}
+	baseDir := filepath.Dir(path)
+	return checkConfigTree(root, baseDir, func() (*entity.WatchConfig, error) {
+		return r.parse(data, baseDir)
+	}), nil
+}
+
+// parse 将YAML内容解析为领域实体，配置中的相对路径基于 baseDir
+func (r *YamlConfigRepository) parse(data []byte, baseDir string) (*entity.WatchConfig, error) {
+	var dto configDTO
+	if err := yaml.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	// 转换为领域实体
+	return dto.toEntity(baseDir)
+}
+
+// LoadUserConfig 从YAML文件加载用户级的全局配置
+func (r *YamlConfigRepository) LoadUserConfig(path string) (*entity.UserConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	var dto userConfigDTO
+	if err := yaml.Unmarshal(data, &dto); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	return dto.toEntity()
+}
+
+// SaveConfig 保存配置到YAML文件
+func (r *YamlConfigRepository) SaveConfig(config *entity.WatchConfig, path string) error {
+	// 转换为DTO
+	dto := newConfigDTO(config)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(dto); err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("序列化配置失败: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+
+	return nil
+}
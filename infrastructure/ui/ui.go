@@ -8,8 +8,8 @@ import (
 	"github.com/watchs/domain/entity"
 )
 
-// ANSI颜色码
-const (
+// ANSI颜色码，禁用彩色输出时为空字符串
+var (
 	Reset  = "\033[0m"
 	Red    = "\033[31m"
 	Green  = "\033[32m"
@@ -21,6 +21,15 @@ const (
 	White  = "\033[97m"
 )
 
+// SetColorEnabled 启用或禁用彩色输出
+func SetColorEnabled(enabled bool) {
+	if !enabled {
+		Reset, Red, Green, Yellow, Blue, Purple, Cyan, Gray, White = "", "", "", "", "", "", "", "", ""
+		return
+	}
+	Reset, Red, Green, Yellow, Blue, Purple, Cyan, Gray, White = "\033[0m", "\033[31m", "\033[32m", "\033[33m", "\033[34m", "\033[35m", "\033[36m", "\033[37m", "\033[97m"
+}
+
 // Emoji图标
 const (
 	CheckMark  = "✅"
@@ -88,6 +97,56 @@ func PrintEvent(event *entity.FileEvent) {
 	fmt.Fprintf(os.Stderr, "%s%s%s %s %s%s%s\n", color, emoji, Reset, event.Path, Gray, fmt.Sprint(event.Type), Reset)
 }
 
+// PrintTaskStatus 打印流水线任务的状态
+func PrintTaskStatus(name string, status entity.TaskStatus, elapsed time.Duration) {
+	var emoji string
+	var color string
+
+	switch status {
+	case entity.TaskRunning:
+		emoji = "▶️"
+		color = Cyan
+	case entity.TaskSucceeded:
+		emoji = CheckMark
+		color = Green
+	case entity.TaskFailed, entity.TaskTimedOut:
+		emoji = CrossMark
+		color = Red
+	case entity.TaskSkipped:
+		emoji = "⏭️"
+		color = Gray
+	default:
+		emoji = "⏹️"
+		color = Yellow
+	}
+
+	if status == entity.TaskRunning || status == entity.TaskSkipped {
+		fmt.Fprintf(os.Stderr, "%s%s [%s] %s%s\n", color, emoji, name, status, Reset)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s%s [%s] %s%s %s(%v)%s\n", color, emoji, name, status, Reset, Gray, elapsed.Round(time.Millisecond), Reset)
+}
+
+// PrintRunResult 打印命令执行结果
+func PrintRunResult(result *entity.RunResult) {
+	elapsed := result.Duration.Round(time.Millisecond)
+
+	switch {
+	case result.TimedOut:
+		PrintError(fmt.Sprintf("命令执行超时，已终止 (耗时 %v)", elapsed))
+	case result.Terminated:
+		PrintInfo(fmt.Sprintf("命令已终止 (耗时 %v)", elapsed))
+	case result.Succeeded():
+		PrintSuccess(fmt.Sprintf("命令执行成功 (耗时 %v)", elapsed))
+	case result.Err != nil:
+		PrintError(fmt.Sprintf("命令执行失败: %v (耗时 %v)", result.Err, elapsed))
+	case result.Signal != "":
+		PrintError(fmt.Sprintf("命令被信号 %s 终止 (耗时 %v)", result.Signal, elapsed))
+	default:
+		PrintError(fmt.Sprintf("命令执行失败，退出码 %d (耗时 %v)", result.ExitCode, elapsed))
+	}
+}
+
 // 预定义的进度条字符，避免重复分配
 var (
 	progressBarFilled = "████████████████████"
@@ -117,7 +176,7 @@ func PrintProgressBar(current, total int, label string) {
 	filled := progressBarFilled[:progress]
 	empty := progressBarEmpty[progress:]
 
-	fmt.Fprintf(os.Stderr, "\r%s [%s%s%s%s%s] %d%% %s",
+	fmt.Fprintf(os.Stderr, "\r%s [%s%s%s%s%s%s] %d%% %s",
 		WatchEmoji,
 		Green, filled, Reset,
 		Gray, empty, Reset,
@@ -0,0 +1,186 @@
+package watcher
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/domain/service"
+	"github.com/watchs/infrastructure/ignore"
+	"github.com/watchs/infrastructure/ui"
+)
+
+// NewWatcherService 根据配置中的后端创建文件监控服务
+func NewWatcherService(config *entity.WatchConfig) (service.WatcherService, error) {
+	switch config.Backend {
+	case entity.BackendFSNotify:
+		return NewFSNotifyWatcher(config)
+	case entity.BackendPoll:
+		return NewPollWatcher(config), nil
+	default:
+		return newAutoWatcher(config), nil
+	}
+}
+
+// autoWatcher 优先使用 fsnotify，在其不可用时回退到轮询
+type autoWatcher struct {
+	config        *entity.WatchConfig
+	active        service.WatcherService
+	eventHandlers []func(event *entity.FileEvent) error
+	mu            sync.Mutex
+}
+
+// newAutoWatcher 创建自动选择后端的文件监控服务
+func newAutoWatcher(config *entity.WatchConfig) *autoWatcher {
+	return &autoWatcher{config: config}
+}
+
+// Start 开始监控文件
+func (w *autoWatcher) Start() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.active != nil {
+		return nil
+	}
+
+	fsWatcher, err := NewFSNotifyWatcher(w.config)
+	if err == nil {
+		w.registerHandlers(fsWatcher)
+		if err = fsWatcher.Start(); err == nil {
+			w.active = fsWatcher
+			return nil
+		}
+	}
+
+	ui.PrintWarning(fmt.Sprintf("fsnotify 不可用 (%v)，改用轮询模式", err))
+	pollWatcher := NewPollWatcher(w.config)
+	w.registerHandlers(pollWatcher)
+	if err := pollWatcher.Start(); err != nil {
+		return err
+	}
+	w.active = pollWatcher
+	return nil
+}
+
+// Stop 停止监控
+func (w *autoWatcher) Stop() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.active == nil {
+		return nil
+	}
+	err := w.active.Stop()
+	w.active = nil
+	return err
+}
+
+// OnFileEvent 注册文件事件处理函数
+func (w *autoWatcher) OnFileEvent(handler func(event *entity.FileEvent) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.eventHandlers = append(w.eventHandlers, handler)
+	if w.active != nil {
+		w.active.OnFileEvent(handler)
+	}
+}
+
+// UpdateConfig 将新的配置交给实际的监控服务
+func (w *autoWatcher) UpdateConfig(config *entity.WatchConfig) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.active != nil {
+		if err := w.active.UpdateConfig(config); err != nil {
+			return err
+		}
+	}
+	w.config = config
+	return nil
+}
+
+// registerHandlers 将已注册的处理函数转交给实际的监控服务
+func (w *autoWatcher) registerHandlers(watcher service.WatcherService) {
+	for _, handler := range w.eventHandlers {
+		watcher.OnFileEvent(handler)
+	}
+}
+
+// configUpdate 是发送给监控 goroutine 的配置切换请求
+type configUpdate struct {
+	config *entity.WatchConfig
+	done   chan error
+}
+
+// sendConfigUpdate 将配置切换请求交给监控 goroutine 处理并等待结果
+// 在监控 goroutine 中切换可以保证切换前后收到的事件都按顺序处理，不会丢失
+func sendConfigUpdate(updateCh chan<- configUpdate, stopCh <-chan struct{}, config *entity.WatchConfig) error {
+	update := configUpdate{config: config, done: make(chan error, 1)}
+	select {
+	case updateCh <- update:
+		return <-update.done
+	case <-stopCh:
+		return fmt.Errorf("监控已停止")
+	}
+}
+
+// newIgnoreMatcher 根据配置创建忽略规则匹配器
+func newIgnoreMatcher(config *entity.WatchConfig) *ignore.Matcher {
+	if config.UseGitignore {
+		return ignore.NewMatcher(config.WatchDir, ignore.GitIgnoreFile, ignore.WatchsIgnoreFile)
+	}
+	return ignore.NewMatcher(config.WatchDir, ignore.WatchsIgnoreFile)
+}
+
+// printWatchInfo 打印监控范围相关的配置
+func printWatchInfo(config *entity.WatchConfig) {
+	if len(config.FileTypes) > 0 {
+		ui.PrintInfo(fmt.Sprintf("监控的文件类型: %v", config.FileTypes))
+	} else {
+		ui.PrintInfo("监控所有文件类型")
+	}
+	if len(config.Include) > 0 {
+		ui.PrintInfo(fmt.Sprintf("包含的模式: %v", config.Include))
+	}
+	if len(config.ExcludePaths) > 0 {
+		ui.PrintInfo(fmt.Sprintf("排除的路径: %v", config.ExcludePaths))
+	}
+	if len(config.Exclude) > 0 {
+		ui.PrintInfo(fmt.Sprintf("排除的模式: %v", config.Exclude))
+	}
+	if config.UseGitignore {
+		ui.PrintInfo("遵循 .gitignore 和 .watchsignore 中的忽略规则")
+	}
+}
+
+// skippedPaths 记录因无法访问而跳过的文件和目录，每个路径只警告一次
+type skippedPaths struct {
+	mu     sync.Mutex
+	warned map[string]bool
+}
+
+// warn 第一次跳过路径时输出警告
+func (s *skippedPaths) warn(path string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.warned[path] {
+		return
+	}
+	if s.warned == nil {
+		s.warned = make(map[string]bool)
+	}
+	s.warned[path] = true
+	ui.PrintWarning(fmt.Sprintf("无法访问 %s，已跳过: %v", path, err))
+}
+
+// notifyHandlers 将事件依次交给所有处理函数
+func notifyHandlers(handlers []func(event *entity.FileEvent) error, event *entity.FileEvent) {
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			log.Printf("处理文件事件失败: %v", err)
+		}
+	}
+}
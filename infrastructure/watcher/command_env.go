@@ -0,0 +1,59 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/watchs/domain/entity"
+)
+
+// runCounter 为运行ID提供进程内唯一的序号
+var runCounter uint64
+
+// newRunID 生成一次命令执行的唯一标识
+// 包含进程ID，同一目录下同时运行的多个 watchs 进程写入同一份执行历史时不会重复
+func newRunID() string {
+	seq := atomic.AddUint64(&runCounter, 1)
+	return fmt.Sprintf("%s-%d-%d", time.Now().Format("20060102T150405"), os.Getpid(), seq)
+}
+
+// applyRunContext 通过环境变量和（可选的）标准输入向子进程传递本次执行的变更信息
+func applyRunContext(cmd *exec.Cmd, runID string, workDir string, events []*entity.FileEvent, options entity.CommandOptions) {
+	files := entity.ChangedFiles(events)
+
+	var event, path string
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		event = last.Type.String()
+		path = last.Path
+	}
+
+	cmd.Env = append(os.Environ(),
+		"WATCHS_EVENT="+event,
+		"WATCHS_PATH="+path,
+		"WATCHS_CHANGED_FILES="+strings.Join(files, "\n"),
+		"WATCHS_RUN_ID="+runID,
+		"WATCHS_WATCH_DIR="+workDir,
+	)
+
+	switch options.FilesStdin {
+	case entity.FilesStdinNewline:
+		cmd.Stdin = strings.NewReader(joinTerminated(files, "\n"))
+	case entity.FilesStdinNul:
+		cmd.Stdin = strings.NewReader(joinTerminated(files, "\x00"))
+	}
+}
+
+// joinTerminated 连接字符串，每一项后面都跟随分隔符
+func joinTerminated(items []string, terminator string) string {
+	var builder strings.Builder
+	for _, item := range items {
+		builder.WriteString(item)
+		builder.WriteString(terminator)
+	}
+	return builder.String()
+}
@@ -2,105 +2,408 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/watchs/domain/entity"
 	"github.com/watchs/infrastructure/ui"
 )
 
+const (
+	// outputWaitDelay 命令退出后等待输出管道关闭的最长时间
+	outputWaitDelay = time.Second
+	// defaultMaxRestarts 监管模式下默认的连续崩溃重启次数上限
+	defaultMaxRestarts = 5
+	// defaultRestartDelay 监管模式下默认的首次重启等待时间
+	defaultRestartDelay = time.Second
+	// maxRestartDelay 监管模式下重启等待时间的上限
+	maxRestartDelay = 30 * time.Second
+	// stableRunDuration 运行超过该时间后退出不计入连续崩溃次数
+	stableRunDuration = 10 * time.Second
+)
+
 // CommandExecutorImpl 是命令执行器的实现
 type CommandExecutorImpl struct {
-	cmd         *exec.Cmd
-	mu          sync.Mutex
-	lastRunTime time.Time
-	debounceMs  int
-	ctx         context.Context
-	cancel      context.CancelFunc
+	name           string
+	current        *commandRun
+	pending        *runRequest
+	crashes        int
+	restartTimer   *time.Timer
+	options        entity.CommandOptions
+	resultHandlers []func(result *entity.RunResult)
+	readyHandlers  []func(result *entity.RunResult)
+	input          *StdinForwarder
+	mu             sync.Mutex
+	ctx            context.Context
+	cancel         context.CancelFunc
 }
 
-// NewCommandExecutor 创建一个新的命令执行器
-func NewCommandExecutor(debounceMs int) *CommandExecutorImpl {
-	if debounceMs <= 0 {
-		debounceMs = 500 // 默认防抖时间为500毫秒
-	}
+// commandRun 表示一次已启动的命令执行
+type commandRun struct {
+	request runRequest
+	cmd     *exec.Cmd
+	// 等待命令结束并转发完输出
+	waitCmd func() error
+	// 向命令的标准输入写入内容，未转发输入时为 nil
+	input  io.Writer
+	result *entity.RunResult
+	output *commandOutput
+	// 最后几行输出，命令结束后写入执行结果
+	lines *lineBuffer
+	// 由 watchs 主动终止时置为 true，在等待进程的goroutine中读取
+	terminated atomic.Bool
+	// 超过 timeout 被终止时置为 true
+	timedOut atomic.Bool
+	// 超时计时器，未设置 timeout 时为 nil
+	timer *time.Timer
+	// 进程被回收且结果已输出后关闭
+	doneCh chan struct{}
+}
 
+// runRequest 表示一次命令执行请求，用于 queue 策略下的排队执行和监管模式下的自动重启
+type runRequest struct {
+	command string
+	workDir string
+	events  []*entity.FileEvent
+}
+
+// NewCommandExecutor 创建一个新的命令执行器，name 用于输出前缀和日志文件名
+// 防抖由应用层的 WatchService 负责，执行器对每次调用都会执行命令
+func NewCommandExecutor(name string, options entity.CommandOptions) *CommandExecutorImpl {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &CommandExecutorImpl{
-		debounceMs: debounceMs,
-		ctx:        ctx,
-		cancel:     cancel,
+		name:    name,
+		options: options,
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 }
 
 // Execute 执行命令
-func (e *CommandExecutorImpl) Execute(command string, workDir string) error {
+// 上一次的命令仍在运行时，设置了 reload_signal 则通知其重新加载，否则按 on_busy 策略重启、排队或忽略
+func (e *CommandExecutorImpl) Execute(command string, workDir string, events []*entity.FileEvent) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	// 防抖：如果两次执行间隔小于设定时间，则忽略
-	now := time.Now()
-	if now.Sub(e.lastRunTime) < time.Duration(e.debounceMs)*time.Millisecond {
-		return nil
+	if e.isBusyUnsafe() && e.options.ReloadSignal != "" {
+		err := e.reloadUnsafe()
+		if err == nil {
+			return nil
+		}
+		ui.PrintWarning(fmt.Sprintf("发送重新加载信号失败: %v，改为重启命令", err))
+	}
+
+	if e.isBusyUnsafe() {
+		switch e.options.OnBusy {
+		case entity.BusyIgnore:
+			ui.PrintInfo("命令仍在执行，忽略本次变更")
+			return nil
+		case entity.BusyQueue:
+			if e.pending == nil {
+				e.pending = &runRequest{command: command, workDir: workDir}
+			}
+			e.pending.events = append(e.pending.events, events...)
+			ui.PrintInfo("命令仍在执行，完成后将再执行一次")
+			return nil
+		}
 	}
-	e.lastRunTime = now
 
-	// 先终止之前的命令
+	// 先终止之前的命令，文件变化触发的执行重新开始计算连续崩溃次数
 	e.terminateUnsafe()
+	e.crashes = 0
+
+	return e.startUnsafe(command, workDir, events)
+}
+
+// Terminate 终止正在执行的命令
+func (e *CommandExecutorImpl) Terminate() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.terminateUnsafe()
+}
+
+// OnRunComplete 注册命令执行结束后的处理函数
+func (e *CommandExecutorImpl) OnRunComplete(handler func(result *entity.RunResult)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.resultHandlers = append(e.resultHandlers, handler)
+}
+
+// OnReady 注册命令通过就绪检查后的处理函数
+func (e *CommandExecutorImpl) OnReady(handler func(result *entity.RunResult)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.readyHandlers = append(e.readyHandlers, handler)
+}
+
+// ForwardInput 将标准输入转发器连接到之后启动的每次命令，需在第一次执行前调用
+func (e *CommandExecutorImpl) ForwardInput(forwarder *StdinForwarder) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.input = forwarder
+}
+
+// isBusyUnsafe 判断上一次启动的命令是否仍在运行（内部使用）
+func (e *CommandExecutorImpl) isBusyUnsafe() bool {
+	if e.current == nil {
+		return false
+	}
+	select {
+	case <-e.current.doneCh:
+		return false
+	default:
+		return true
+	}
+}
+
+// reloadUnsafe 向正在运行的命令的进程组发送重新加载信号（内部使用）
+func (e *CommandExecutorImpl) reloadUnsafe() error {
+	// 信号名称已在配置校验时检查过
+	signal, _ := entity.NormalizeSignal(e.options.ReloadSignal)
+	if err := reloadProcessGroup(e.current.cmd, signal); err != nil {
+		return err
+	}
+	ui.PrintInfo(fmt.Sprintf("向命令发送 %s 信号重新加载", signal))
+	return nil
+}
+
+// startUnsafe 在不加锁的情况下启动命令，并在后台等待其结束（内部使用）
+func (e *CommandExecutorImpl) startUnsafe(template string, workDir string, events []*entity.FileEvent) error {
+	// 使用变更文件展开命令模板
+	command, err := expandCommand(template, workDir, events)
+	if err != nil {
+		return err
+	}
 
 	ui.PrintInfo(fmt.Sprintf("执行命令: %s", command))
 
-	// 根据操作系统选择不同的命令执行方式，使用context进行管理
-	var cmd *exec.Cmd
-	if os.PathSeparator == '\\' { // Windows
-		cmd = exec.CommandContext(e.ctx, "cmd", "/c", command)
-	} else { // Unix
-		cmd = exec.CommandContext(e.ctx, "/bin/sh", "-c", command)
+	runID := newRunID()
+	cmd := newShellCommand(e.ctx, command, workDir)
+	applyRunContext(cmd, runID, workDir, events, e.options)
+
+	lines := newLineBuffer(outputLines(e.options.Output))
+	run := &commandRun{
+		request: runRequest{command: template, workDir: workDir, events: events},
+		cmd:     cmd,
+		result:  entity.NewRunResult(runID, command, events),
+		output:  newCommandOutput(e.name, workDir, e.options.Output, lines),
+		lines:   lines,
+		doneCh:  make(chan struct{}),
 	}
+	run.output.attach(cmd)
+	run.output.note(fmt.Sprintf("执行命令: %s", command))
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Dir = workDir
+	var logs *logMatcher
+	if probe := e.options.Ready; probe != nil && probe.LogPattern != "" {
+		// 模式已在配置校验时检查过
+		logs = newLogMatcher(regexp.MustCompile(probe.LogPattern))
+		captureOutput(cmd, logs)
+	}
+
+	waitCmd, input, err := startCommand(cmd, e.options, e.input != nil)
+	if err != nil {
+		run.output.Close()
+		return err
+	}
 
-	e.cmd = cmd
-	return cmd.Start()
+	run.waitCmd = waitCmd
+	run.input = input
+	e.current = run
+	if e.input != nil {
+		e.input.attach(input)
+	}
+	if timeout := e.options.Timeout; timeout > 0 {
+		run.timer = time.AfterFunc(timeout, func() {
+			e.timeout(run, timeout)
+		})
+	}
+	go e.wait(run)
+	if e.options.Ready != nil {
+		go e.probeReady(run, logs)
+	}
+	return nil
 }
 
-// Terminate 终止正在执行的命令
-func (e *CommandExecutorImpl) Terminate() error {
+// probeReady 等待命令通过就绪检查，就绪后通知处理函数
+func (e *CommandExecutorImpl) probeReady(run *commandRun, logs *logMatcher) {
+	if err := waitReady(e.options.Ready, logs, run.doneCh); err != nil {
+		// 被 watchs 终止的命令不再提示
+		if !run.terminated.Load() {
+			ui.PrintWarning(fmt.Sprintf("就绪检查失败: %v", err))
+		}
+		return
+	}
+
+	started := run.result.StartTime
+	ui.PrintSuccess(fmt.Sprintf("命令已就绪 (耗时 %v)", time.Since(started).Round(time.Millisecond)))
+
+	// 只复制启动时确定的字段，其余字段会在命令结束时由等待goroutine写入
+	ready := &entity.RunResult{
+		RunID:     run.result.RunID,
+		Command:   run.result.Command,
+		Events:    run.result.Events,
+		Files:     run.result.Files,
+		StartTime: started,
+	}
+
+	e.mu.Lock()
+	handlers := e.readyHandlers
+	e.mu.Unlock()
+	for _, handler := range handlers {
+		handler(ready)
+	}
+}
+
+// timeout 在命令超过最长执行时间后终止其进程组
+func (e *CommandExecutorImpl) timeout(run *commandRun, timeout time.Duration) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	return e.terminateUnsafe()
+	// 命令已结束、被终止或替换
+	if e.current != run || !e.isBusyUnsafe() {
+		return
+	}
+
+	ui.PrintWarning(fmt.Sprintf("命令执行超过 %v，正在终止", timeout))
+	run.timedOut.Store(true)
+	if err := stopProcess(run.cmd, run.doneCh, e.options); err != nil {
+		ui.PrintError(fmt.Sprintf("终止超时的命令失败: %v", err))
+	}
+}
+
+// wait 在后台等待命令结束，记录并输出执行结果，然后执行排队中的请求
+func (e *CommandExecutorImpl) wait(run *commandRun) {
+	err := run.waitCmd()
+	if run.timer != nil {
+		run.timer.Stop()
+	}
+	if e.input != nil {
+		e.input.detach(run.input)
+	}
+	run.output.Close()
+	finishRunResult(run.result, run.cmd, err)
+	run.result.Output = run.lines.String()
+	run.result.Terminated = run.terminated.Load()
+	run.result.TimedOut = run.timedOut.Load()
+	ui.PrintRunResult(run.result)
+	close(run.doneCh)
+
+	e.mu.Lock()
+	handlers := e.resultHandlers
+	e.mu.Unlock()
+	for _, handler := range handlers {
+		handler(run.result)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// 命令已被终止或替换
+	if e.current != run {
+		return
+	}
+	e.current = nil
+
+	if e.pending != nil {
+		next := e.pending
+		e.pending = nil
+		if err := e.startUnsafe(next.command, next.workDir, next.events); err != nil {
+			ui.PrintError(fmt.Sprintf("执行排队的命令失败: %v", err))
+		}
+		return
+	}
+
+	// 监管模式下，非 watchs 主动终止的退出视为崩溃
+	if e.options.Supervised() && !run.result.Terminated && !run.result.TimedOut {
+		e.scheduleRestartUnsafe(run)
+	}
+}
+
+// scheduleRestartUnsafe 按指数退避安排崩溃命令的自动重启，连续崩溃超过上限后停止（内部使用）
+func (e *CommandExecutorImpl) scheduleRestartUnsafe(run *commandRun) {
+	// 稳定运行一段时间后的退出重新开始计数
+	if run.result.Duration >= stableRunDuration {
+		e.crashes = 0
+	}
+	e.crashes++
+
+	maxRestarts := e.options.MaxRestarts
+	if maxRestarts <= 0 {
+		maxRestarts = defaultMaxRestarts
+	}
+	if e.crashes > maxRestarts {
+		ui.PrintError(fmt.Sprintf("命令自动重启 %d 次后仍然退出，停止自动重启，文件变化后将再次执行", maxRestarts))
+		return
+	}
+
+	delay := e.options.RestartDelay
+	if delay <= 0 {
+		delay = defaultRestartDelay
+	}
+	for i := 1; i < e.crashes && delay < maxRestartDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRestartDelay {
+		delay = maxRestartDelay
+	}
+
+	ui.PrintWarning(fmt.Sprintf("命令意外退出，%v 后自动重启 (%d/%d)", delay, e.crashes, maxRestarts))
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		// 重启已被取消，或期间文件变化已重新执行命令
+		if e.restartTimer != timer || e.current != nil {
+			return
+		}
+		e.restartTimer = nil
+
+		ui.PrintInfo("正在重启命令...")
+		request := run.request
+		if err := e.startUnsafe(request.command, request.workDir, request.events); err != nil {
+			ui.PrintError(fmt.Sprintf("重启命令失败: %v", err))
+		}
+	})
+	e.restartTimer = timer
 }
 
 // terminateUnsafe 在不加锁的情况下终止命令（内部使用）
 func (e *CommandExecutorImpl) terminateUnsafe() error {
-	if e.cmd == nil || e.cmd.Process == nil {
-		return nil
+	e.pending = nil
+	if e.restartTimer != nil {
+		e.restartTimer.Stop()
+		e.restartTimer = nil
 	}
 
-	var err error
-
-	// 在 Windows 上使用 taskkill 来终止进程树
-	if os.PathSeparator == '\\' { // Windows
-		killCmd := exec.Command("taskkill", "/F", "/T", "/PID", fmt.Sprintf("%d", e.cmd.Process.Pid))
-		killCmd.Run() // 忽略taskkill的错误，因为进程可能已经结束
-	} else { // Unix
-		err = e.cmd.Process.Kill()
+	if e.current == nil {
+		return nil
 	}
 
-	// 等待进程结束，避免僵尸进程
-	waitErr := e.cmd.Wait()
-	if err == nil {
-		err = waitErr
+	// 进程已经退出，只需等待后台goroutine完成回收
+	if !e.isBusyUnsafe() {
+		e.current = nil
+		return nil
 	}
 
-	e.cmd = nil
-	return err
+	// 先发送终止信号，宽限期后强制结束整个进程组
+	run := e.current
+	e.current = nil
+	run.terminated.Store(true)
+	return stopProcess(run.cmd, run.doneCh, e.options)
 }
 
 // Close 清理资源
@@ -108,11 +411,56 @@ func (e *CommandExecutorImpl) Close() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	// 取消context，这会终止所有使用该context的命令
+	// 优雅地终止当前命令
+	err := e.terminateUnsafe()
+
+	// 取消context，确保所有使用该context的命令都已结束
 	if e.cancel != nil {
 		e.cancel()
 	}
 
-	// 终止当前命令
-	return e.terminateUnsafe()
+	return err
+}
+
+// newShellCommand 创建通过系统 shell 执行的命令，输出直接转发到终端
+func newShellCommand(ctx context.Context, command string, workDir string) *exec.Cmd {
+	// 根据操作系统选择不同的命令执行方式，使用context进行管理
+	var cmd *exec.Cmd
+	if os.PathSeparator == '\\' { // Windows
+		cmd = exec.CommandContext(ctx, "cmd", "/c", command)
+	} else { // Unix
+		cmd = exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	}
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Dir = workDir
+	// 输出经过管道转发时，防止后台子进程持有管道导致命令无法被回收
+	cmd.WaitDelay = outputWaitDelay
+
+	// 在独立的进程组中运行，context 取消时结束整个进程组而不只是 shell
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+	return cmd
+}
+
+// finishRunResult 根据等待命令的结果补全执行耗时、退出码和终止信号
+func finishRunResult(result *entity.RunResult, cmd *exec.Cmd, err error) {
+	result.Duration = time.Since(result.StartTime)
+
+	if cmd.ProcessState == nil {
+		result.ExitCode = -1
+		result.Err = err
+		return
+	}
+	result.ExitCode = cmd.ProcessState.ExitCode()
+	result.Signal = exitSignal(cmd.ProcessState)
+
+	// 非零退出码已经记录在 ExitCode 中，只保留其他错误
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && !errors.Is(err, exec.ErrWaitDelay) {
+		result.Err = err
+	}
 }
@@ -0,0 +1,34 @@
+package watcher
+
+import (
+	"io"
+	"os/exec"
+
+	"github.com/watchs/domain/entity"
+)
+
+// startCommand 启动命令，设置了 pty 时在伪终端中运行
+// 返回的函数等待命令结束，并在返回前转发完命令的输出
+// withInput 为 true 时同时返回向命令的标准输入写入内容的 io.Writer
+func startCommand(cmd *exec.Cmd, options entity.CommandOptions, withInput bool) (func() error, io.Writer, error) {
+	if options.UsePTY() {
+		return startPTY(cmd, withInput)
+	}
+	return startPlain(cmd, withInput)
+}
+
+// startPlain 以普通方式启动命令，标准输入通过管道写入
+func startPlain(cmd *exec.Cmd, withInput bool) (func() error, io.Writer, error) {
+	var input io.Writer
+	if withInput {
+		pipe, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		input = pipe
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	return cmd.Wait, input, nil
+}
@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/watchs/domain/entity"
+)
+
+// commandTemplateData 是命令模板中可用的占位符
+// 单个文件的占位符取自批次中最后一个事件，来自文件路径的值默认经过 shell 转义
+type commandTemplateData struct {
+	// 文件的绝对路径
+	Path quotedValue
+	// 相对于监控目录的路径
+	RelPath quotedValue
+	// 相对于监控目录的所在目录，位于监控目录根部时为 "."
+	Dir quotedValue
+	// 文件扩展名，如 ".go"
+	Ext quotedValue
+	// 事件类型，如 "write"
+	Event string
+	// 批次中所有变更文件相对于监控目录的路径，去重并经过 shell 转义，以空格分隔
+	Files quotedValue
+	// 未经转义的原始值，用于拼接到已加引号的字符串中
+	Raw rawTemplateData
+}
+
+// rawTemplateData 是未经 shell 转义的单文件占位符
+type rawTemplateData struct {
+	Path    string
+	RelPath string
+	Dir     string
+	Ext     string
+}
+
+// quotedValue 是已经过 shell 转义的值，quote 不会再次转义
+type quotedValue string
+
+// expandCommand 使用触发本次执行的文件事件展开命令中的模板占位符
+func expandCommand(command string, workDir string, events []*entity.FileEvent) (string, error) {
+	if !strings.Contains(command, "{{") {
+		return command, nil
+	}
+
+	tmpl, err := template.New("command").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"quote": quoteTemplateValue}).
+		Parse(command)
+	if err != nil {
+		return "", fmt.Errorf("解析命令模板失败: %w", err)
+	}
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, newCommandTemplateData(workDir, events)); err != nil {
+		return "", fmt.Errorf("展开命令模板失败: %w", err)
+	}
+	return builder.String(), nil
+}
+
+// newCommandTemplateData 根据事件批次生成模板数据
+func newCommandTemplateData(workDir string, events []*entity.FileEvent) commandTemplateData {
+	data := commandTemplateData{Dir: ".", Raw: rawTemplateData{Dir: "."}}
+	if len(events) == 0 {
+		return data
+	}
+
+	var files []string
+	seen := make(map[string]bool, len(events))
+	for _, event := range events {
+		rel := relativePath(workDir, event.Path)
+		if !seen[rel] {
+			seen[rel] = true
+			files = append(files, shellQuote(rel))
+		}
+	}
+
+	last := events[len(events)-1]
+	rel := relativePath(workDir, last.Path)
+	data.Raw = rawTemplateData{
+		Path:    last.Path,
+		RelPath: rel,
+		Dir:     path.Dir(rel),
+		Ext:     path.Ext(rel),
+	}
+	data.Path = quoteNonEmpty(data.Raw.Path)
+	data.RelPath = quoteNonEmpty(data.Raw.RelPath)
+	data.Dir = quoteNonEmpty(data.Raw.Dir)
+	data.Ext = quoteNonEmpty(data.Raw.Ext)
+	data.Event = last.Type.String()
+	data.Files = quotedValue(strings.Join(files, " "))
+	return data
+}
+
+// quoteNonEmpty 转义非空的值，初始执行时没有变更文件，占位符展开为空
+func quoteNonEmpty(value string) quotedValue {
+	if value == "" {
+		return ""
+	}
+	return quotedValue(shellQuote(value))
+}
+
+// quoteTemplateValue 是模板中的 quote 函数，转义任意值，已转义的值原样返回
+func quoteTemplateValue(value any) quotedValue {
+	if quoted, ok := value.(quotedValue); ok {
+		return quoted
+	}
+	return quotedValue(shellQuote(fmt.Sprint(value)))
+}
+
+// relativePath 返回相对于 base、以 / 分隔的路径，无法计算时返回原路径
+func relativePath(base, target string) string {
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return filepath.ToSlash(target)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// safeShellArg 匹配不需要转义的参数，只含这些字符的路径保持原样，命令更易读
+var safeShellArg = regexp.MustCompile(`^[A-Za-z0-9_./:@+-]+$`)
+
+// shellQuote 转义参数，使其可以安全地拼接到 shell 命令中，不含特殊字符的参数保持原样
+func shellQuote(arg string) string {
+	if os.PathSeparator == '\\' { // Windows，路径分隔符 \ 在 cmd 中不需要转义
+		if safeShellArg.MatchString(strings.ReplaceAll(arg, `\`, "/")) {
+			return arg
+		}
+		return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	}
+	if safeShellArg.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
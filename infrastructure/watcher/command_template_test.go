@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/watchs/domain/entity"
+)
+
+// skipOnWindows 转义的期望结果按 /bin/sh 的单引号规则编写
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if os.PathSeparator == '\\' {
+		t.Skip("期望结果使用 /bin/sh 的转义规则")
+	}
+}
+
+// TestExpandCommandQuoting 包含空格和单引号的路径经过转义，普通路径保持原样
+func TestExpandCommandQuoting(t *testing.T) {
+	skipOnWindows(t)
+	workDir := "/project"
+	events := []*entity.FileEvent{
+		entity.NewFileEvent("/project/pkg/api/server.go", entity.EventWrite),
+		entity.NewFileEvent("/project/my docs/it's.md", entity.EventCreate),
+	}
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"cat {{.Path}}", `cat '/project/my docs/it'\''s.md'`},
+		{"cat {{.RelPath}}", `cat 'my docs/it'\''s.md'`},
+		{"ls {{.Dir}}", `ls 'my docs'`},
+		{"echo {{.Ext}} {{.Event}}", "echo .md create"},
+		{"lint {{.Files}}", `lint pkg/api/server.go 'my docs/it'\''s.md'`},
+		{"echo \"{{.Raw.RelPath}}\"", `echo "my docs/it's.md"`},
+		{"no placeholders $HOME", "no placeholders $HOME"},
+	}
+	for _, tt := range tests {
+		got, err := expandCommand(tt.command, workDir, events)
+		if err != nil {
+			t.Errorf("expandCommand(%q) error = %v", tt.command, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandCommand(%q) = %s, want %s", tt.command, got, tt.want)
+		}
+	}
+}
+
+// TestExpandCommandFilesDeduplicated 同一文件的多个事件在 {{.Files}} 中只出现一次，保持第一次出现的顺序
+func TestExpandCommandFilesDeduplicated(t *testing.T) {
+	workDir := filepath.Join(string(filepath.Separator), "project")
+	a := filepath.Join(workDir, "a.go")
+	b := filepath.Join(workDir, "pkg", "b.go")
+	events := []*entity.FileEvent{
+		entity.NewFileEvent(a, entity.EventCreate),
+		entity.NewFileEvent(b, entity.EventWrite),
+		entity.NewFileEvent(a, entity.EventWrite),
+		entity.NewFileEvent(b, entity.EventChmod),
+	}
+
+	got, err := expandCommand("gofmt -l {{.Files}}", workDir, events)
+	if err != nil {
+		t.Fatalf("expandCommand() error = %v", err)
+	}
+	if want := "gofmt -l a.go pkg/b.go"; got != want {
+		t.Errorf("expandCommand() = %s, want %s", got, want)
+	}
+}
+
+// TestExpandCommandInitialRun 初始执行没有变更文件，{{.Dir}} 为 "."，其余占位符为空
+func TestExpandCommandInitialRun(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"go test ./{{.Dir}}", "go test ./."},
+		{"go test ./{{.Raw.Dir}}/...", "go test ././..."},
+		{"echo [{{.Path}}][{{.RelPath}}][{{.Ext}}][{{.Event}}][{{.Files}}]", "echo [][][][][]"},
+	}
+	for _, tt := range tests {
+		got, err := expandCommand(tt.command, "/project", nil)
+		if err != nil {
+			t.Errorf("expandCommand(%q) error = %v", tt.command, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandCommand(%q) = %s, want %s", tt.command, got, tt.want)
+		}
+	}
+}
+
+// TestExpandCommandQuoteFunc quote 转义任意值，已转义的占位符不会重复转义
+func TestExpandCommandQuoteFunc(t *testing.T) {
+	skipOnWindows(t)
+	events := []*entity.FileEvent{entity.NewFileEvent("/project/my dir/a b.txt", entity.EventWrite)}
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"cat {{quote .Raw.Path}}", `cat '/project/my dir/a b.txt'`},
+		{"cat {{quote .Path}}", `cat '/project/my dir/a b.txt'`},
+		{"cat {{.Path | quote}}", `cat '/project/my dir/a b.txt'`},
+		{"echo {{quote \"it's\"}}", `echo 'it'\''s'`},
+		{"echo {{quote \"plain\"}}", "echo plain"},
+	}
+	for _, tt := range tests {
+		got, err := expandCommand(tt.command, "/project", events)
+		if err != nil {
+			t.Errorf("expandCommand(%q) error = %v", tt.command, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandCommand(%q) = %s, want %s", tt.command, got, tt.want)
+		}
+	}
+}
+
+// TestExpandCommandErrors 未知的占位符和语法错误返回错误
+func TestExpandCommandErrors(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"echo {{.Unknown}}", "展开命令模板失败"},
+		{"echo {{.Raw.Unknown}}", "展开命令模板失败"},
+		{"echo {{.Path", "解析命令模板失败"},
+		{"echo {{shout .Path}}", "解析命令模板失败"},
+	}
+	for _, tt := range tests {
+		_, err := expandCommand(tt.command, "/project", nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("expandCommand(%q) error = %v, want %q", tt.command, err, tt.want)
+		}
+	}
+}
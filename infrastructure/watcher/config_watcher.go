@@ -0,0 +1,81 @@
+package watcher
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// configPollInterval 检查配置文件是否变化的间隔
+const configPollInterval = 500 * time.Millisecond
+
+// ConfigFileWatcher 定期检查配置文件的修改时间和大小，在文件变化后调用回调函数
+// 使用轮询而不是 fsnotify，编辑器以重命名方式保存文件时也能可靠地发现变化
+type ConfigFileWatcher struct {
+	path     string
+	onChange func()
+	stopOnce sync.Once
+	stopCh   chan struct{}
+	doneCh   chan struct{}
+}
+
+// NewConfigFileWatcher 创建一个新的配置文件监控器
+func NewConfigFileWatcher(path string, onChange func()) *ConfigFileWatcher {
+	return &ConfigFileWatcher{
+		path:     path,
+		onChange: onChange,
+		stopCh:   make(chan struct{}),
+		doneCh:   make(chan struct{}),
+	}
+}
+
+// Start 开始监控配置文件
+func (w *ConfigFileWatcher) Start() {
+	go w.poll(w.stat())
+}
+
+// Stop 停止监控，并等待正在执行的回调函数结束
+func (w *ConfigFileWatcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+		<-w.doneCh
+	})
+}
+
+// poll 定期检查配置文件的状态
+// 发现变化后等到文件在下一次检查时保持不变再调用回调函数，避免读到编辑器写了一半的文件
+func (w *ConfigFileWatcher) poll(last fileState) {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	pending := false
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			state := w.stat()
+			if !state.modTime.Equal(last.modTime) || state.size != last.size {
+				last = state
+				pending = true
+				continue
+			}
+			// 文件被删除时等待它重新出现
+			if pending && !state.modTime.IsZero() {
+				pending = false
+				w.onChange()
+			}
+		}
+	}
+}
+
+// stat 返回配置文件当前的状态，文件不存在时返回零值
+func (w *ConfigFileWatcher) stat() fileState {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size()}
+}
@@ -1,7 +1,9 @@
 package watcher
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ignore"
 	"github.com/watchs/infrastructure/ui"
 )
 
@@ -16,11 +19,14 @@ import (
 type FSNotifyWatcher struct {
 	config        *entity.WatchConfig
 	watcher       *fsnotify.Watcher
+	ignore        *ignore.Matcher
 	eventHandlers []func(event *entity.FileEvent) error
 	mu            sync.RWMutex
 	isRunning     bool
 	stopCh        chan struct{}
 	doneCh        chan struct{}
+	updateCh      chan configUpdate
+	skipped       skippedPaths
 }
 
 // NewFSNotifyWatcher 创建一个新的fsnotify文件监控器
@@ -33,9 +39,11 @@ func NewFSNotifyWatcher(config *entity.WatchConfig) (*FSNotifyWatcher, error) {
 	return &FSNotifyWatcher{
 		config:    config,
 		watcher:   watcher,
+		ignore:    newIgnoreMatcher(config),
 		isRunning: false,
 		stopCh:    make(chan struct{}),
 		doneCh:    make(chan struct{}),
+		updateCh:  make(chan configUpdate),
 	}, nil
 }
 
@@ -49,20 +57,17 @@ func (w *FSNotifyWatcher) Start() error {
 	w.isRunning = true
 	w.mu.Unlock()
 
-	// 添加初始监控目录
-	if err := w.addWatchDir(w.config.WatchDir); err != nil {
+	// 添加初始监控目录，任何目录注册失败都视为启动失败，以便 auto 模式回退到轮询
+	if err := w.addWatchDir(w.config.WatchDir, true); err != nil {
+		w.mu.Lock()
+		w.isRunning = false
+		w.mu.Unlock()
+		w.watcher.Close()
 		return err
 	}
 
 	ui.PrintSuccess(fmt.Sprintf("开始监控目录: %s", w.config.WatchDir))
-	if len(w.config.FileTypes) > 0 {
-		ui.PrintInfo(fmt.Sprintf("监控的文件类型: %v", w.config.FileTypes))
-	} else {
-		ui.PrintInfo("监控所有文件类型")
-	}
-	if len(w.config.ExcludePaths) > 0 {
-		ui.PrintInfo(fmt.Sprintf("排除的路径: %v", w.config.ExcludePaths))
-	}
+	printWatchInfo(w.config)
 
 	// 监听事件
 	go w.watchEvents()
@@ -101,37 +106,115 @@ func (w *FSNotifyWatcher) OnFileEvent(handler func(event *entity.FileEvent) erro
 	w.eventHandlers = append(w.eventHandlers, handler)
 }
 
-// 添加监控目录（递归）
-func (w *FSNotifyWatcher) addWatchDir(dir string) error {
-	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
+// UpdateConfig 切换到新的配置，并按新的监控目录和排除规则增减注册的目录
+func (w *FSNotifyWatcher) UpdateConfig(config *entity.WatchConfig) error {
+	w.mu.RLock()
+	running := w.isRunning
+	w.mu.RUnlock()
 
-		// 如果是目录，检查是否应该排除
-		if info.IsDir() {
-			for _, excludePath := range w.config.ExcludePaths {
-				absExcludePath, err := filepath.Abs(excludePath)
-				if err == nil && (path == absExcludePath || filepath.HasPrefix(path, absExcludePath+string(os.PathSeparator))) {
-					return filepath.SkipDir
-				}
-
-				// 支持通配符匹配目录名
-				matched, err := filepath.Match(excludePath, filepath.Base(path))
-				if err == nil && matched {
-					return filepath.SkipDir
-				}
+	if !running {
+		w.config = config
+		w.ignore = newIgnoreMatcher(config)
+		return nil
+	}
+	return sendConfigUpdate(w.updateCh, w.stopCh, config)
+}
+
+// applyConfig 在事件循环中切换配置：先注册新配置需要的目录，再移除不再需要的目录
+// 新目录注册失败时撤销已注册的目录并保留旧配置
+func (w *FSNotifyWatcher) applyConfig(config *entity.WatchConfig) error {
+	watched := make(map[string]bool)
+	for _, dir := range w.watcher.WatchList() {
+		watched[dir] = true
+	}
+
+	matcher := newIgnoreMatcher(config)
+	wanted := make(map[string]bool)
+	var added []string
+	err := walkWatchDirs(config.WatchDir, config, matcher, &w.skipped, func(dir string) error {
+		wanted[dir] = true
+		if watched[dir] {
+			return nil
+		}
+		if err := w.watcher.Add(dir); err != nil {
+			if dir != config.WatchDir && errors.Is(err, fs.ErrPermission) {
+				w.skipped.warn(dir, err)
+				return nil
 			}
+			return fmt.Errorf("添加监控目录失败 %s: %w", dir, err)
+		}
+		added = append(added, dir)
+		return nil
+	})
+	if err != nil {
+		for _, dir := range added {
+			w.watcher.Remove(dir)
+		}
+		return err
+	}
+
+	for dir := range watched {
+		if !wanted[dir] {
+			w.watcher.Remove(dir)
+		}
+	}
+
+	w.config = config
+	w.ignore = matcher
+	printWatchInfo(config)
+	return nil
+}
 
-			// 添加目录到监控
-			if err := w.watcher.Add(path); err != nil {
-				log.Printf("添加监控目录失败 %s: %v", path, err)
+// 添加监控目录（递归），strict 为 true 时遇到注册失败立即返回错误
+func (w *FSNotifyWatcher) addWatchDir(dir string, strict bool) error {
+	return walkWatchDirs(dir, w.config, w.ignore, &w.skipped, func(path string) error {
+		if err := w.watcher.Add(path); err != nil {
+			// 没有权限的子目录跳过，不影响其余目录的监控
+			if path != w.config.WatchDir && errors.Is(err, fs.ErrPermission) {
+				w.skipped.warn(path, err)
+				return nil
 			}
+			if strict {
+				return fmt.Errorf("添加监控目录失败 %s: %w", path, err)
+			}
+			log.Printf("添加监控目录失败 %s: %v", path, err)
 		}
 		return nil
 	})
 }
 
+// walkWatchDirs 递归遍历 dir 下需要监控的目录，跳过排除的目录和忽略文件中声明的目录
+// dir 本身无法访问时返回错误，其中无法访问的目录跳过并记录到 skipped
+func walkWatchDirs(dir string, config *entity.WatchConfig, matcher *ignore.Matcher, skipped *skippedPaths, visit func(dir string) error) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			// 遍历过程中被删除的目录直接跳过
+			if !os.IsNotExist(err) {
+				skipped.warn(path, err)
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		// 检查目录是否应该排除
+		if config.ShouldSkipDir(path) {
+			return filepath.SkipDir
+		}
+
+		// 跳过忽略文件中声明的目录
+		if path != config.WatchDir && matcher.Match(path, true) {
+			return filepath.SkipDir
+		}
+
+		return visit(filepath.Clean(path))
+	})
+}
+
 // 监听文件变化事件
 func (w *FSNotifyWatcher) watchEvents() {
 	defer close(w.doneCh) // 确保在退出时关闭doneCh
@@ -142,18 +225,32 @@ func (w *FSNotifyWatcher) watchEvents() {
 			// 收到停止信号，退出goroutine
 			return
 
+		case update := <-w.updateCh:
+			update.done <- w.applyConfig(update.config)
+
 		case event, ok := <-w.watcher.Events:
 			if !ok {
 				return
 			}
 
-			// 检查是否是创建目录事件
-			if event.Has(fsnotify.Create) {
-				info, err := os.Stat(event.Name)
-				if err == nil && info.IsDir() {
-					// 如果是新创建的目录，添加到监控
-					w.addWatchDir(event.Name)
-				}
+			// 忽略文件变化后重新加载该目录的规则
+			if w.ignore.IsIgnoreFile(event.Name) {
+				w.ignore.Invalidate(filepath.Dir(event.Name))
+			}
+
+			isDir := false
+			if info, err := os.Stat(event.Name); err == nil {
+				isDir = info.IsDir()
+			}
+
+			// 跳过被 .gitignore / .watchsignore 忽略的路径
+			if w.ignore.Match(event.Name, isDir) {
+				continue
+			}
+
+			// 如果是新创建的目录，添加到监控
+			if event.Has(fsnotify.Create) && isDir {
+				w.addWatchDir(event.Name, false)
 			}
 
 			// 检查是否应该监控此文件
@@ -184,12 +281,7 @@ func (w *FSNotifyWatcher) watchEvents() {
 			w.mu.RLock()
 			handlers := w.eventHandlers
 			w.mu.RUnlock()
-
-			for _, handler := range handlers {
-				if err := handler(fileEvent); err != nil {
-					log.Printf("处理文件事件失败: %v", err)
-				}
-			}
+			notifyHandlers(handlers, fileEvent)
 
 		case err, ok := <-w.watcher.Errors:
 			if !ok {
@@ -0,0 +1,56 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ui"
+)
+
+// HookRunnerImpl 是生命周期钩子执行器的实现
+type HookRunnerImpl struct {
+	options entity.CommandOptions
+}
+
+// NewHookRunner 创建一个新的钩子执行器，钩子使用全局的命令执行选项
+func NewHookRunner(options entity.CommandOptions) *HookRunnerImpl {
+	return &HookRunnerImpl{
+		options: options,
+	}
+}
+
+// RunHook 同步执行钩子命令
+// 钩子的命令模板、环境变量和标准输入与触发它的命令相同，并额外提供 WATCHS_HOOK 和 WATCHS_EXIT_CODE
+func (h *HookRunnerImpl) RunHook(hook entity.HookType, command string, workDir string, result *entity.RunResult) error {
+	runID := newRunID()
+	var events []*entity.FileEvent
+	if result != nil {
+		runID = result.RunID
+		events = result.Events
+	}
+
+	command, err := expandCommand(command, workDir, events)
+	if err != nil {
+		return err
+	}
+
+	ui.PrintInfo(fmt.Sprintf("执行钩子 %s: %s", hook, command))
+
+	// 钩子同步执行，设置了 timeout 时同样限制其执行时间
+	ctx := context.Background()
+	if h.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.Timeout)
+		defer cancel()
+	}
+
+	cmd := newShellCommand(ctx, command, workDir)
+	applyRunContext(cmd, runID, workDir, events, h.options)
+	cmd.Env = append(cmd.Env, "WATCHS_HOOK="+string(hook))
+	if hook == entity.HookOnSuccess || hook == entity.HookOnFailure {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("WATCHS_EXIT_CODE=%d", result.ExitCode))
+	}
+
+	return cmd.Run()
+}
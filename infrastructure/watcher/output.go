@@ -0,0 +1,247 @@
+package watcher
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ui"
+)
+
+const (
+	// defaultOutputLines 执行结果中默认保留的输出行数
+	defaultOutputLines = 50
+	// maxOutputLine 保留和写入日志的单行最大字节数，未换行的输出超过该长度时按一行处理
+	maxOutputLine = 4096
+	// logDirName 日志文件所在的目录，位于监控目录的 .watchs 目录下
+	logDirName = "logs"
+)
+
+// lineBuffer 只保留最后若干行输出的环形缓冲，可被多个 goroutine 同时写入
+type lineBuffer struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// newLineBuffer 创建保留最后 size 行的缓冲
+func newLineBuffer(size int) *lineBuffer {
+	return &lineBuffer{lines: make([]string, size)}
+}
+
+// add 追加一行，缓冲已满时覆盖最旧的一行
+func (b *lineBuffer) add(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// String 按从旧到新的顺序返回保留的行
+func (b *lineBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lines := b.lines[:b.next]
+	if b.full {
+		lines = append(append([]string{}, b.lines[b.next:]...), b.lines[:b.next]...)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// outputLines 返回执行结果中保留的输出行数
+func outputLines(options entity.OutputOptions) int {
+	if options.Lines > 0 {
+		return options.Lines
+	}
+	return defaultOutputLines
+}
+
+// commandOutput 处理一次命令执行的输出
+// 按选项为每行添加命令名称和时间后写入终端，同时写入日志文件并将最后几行保留在 lines 中
+type commandOutput struct {
+	name    string
+	color   string
+	options entity.OutputOptions
+	lines   *lineBuffer
+	log     *rotatingLog
+	mu      sync.Mutex
+	streams []*outputStream
+}
+
+// outputStream 是命令的标准输出或标准错误，缓存尚未换行的内容
+type outputStream struct {
+	output  *commandOutput
+	dst     io.Writer
+	pending []byte
+}
+
+// newCommandOutput 创建命令的输出处理，日志文件无法打开时只输出警告
+func newCommandOutput(name string, workDir string, options entity.OutputOptions, lines *lineBuffer) *commandOutput {
+	output := &commandOutput{
+		name:    name,
+		color:   prefixColor(name),
+		options: options,
+		lines:   lines,
+	}
+	if options.HasLogFile() {
+		path := filepath.Join(workDir, entity.StateDirName, logDirName, logFileName(name))
+		log, err := openRotatingLog(path)
+		if err != nil {
+			ui.PrintWarning(fmt.Sprintf("打开日志文件失败: %v", err))
+		} else {
+			output.log = log
+		}
+	}
+	return output
+}
+
+// attach 将命令的标准输出和标准错误连接到输出处理
+func (o *commandOutput) attach(cmd *exec.Cmd) {
+	cmd.Stdout = o.stream(os.Stdout)
+	cmd.Stderr = o.stream(os.Stderr)
+}
+
+// stream 创建写入 dst 的输出流
+func (o *commandOutput) stream(dst io.Writer) io.Writer {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	stream := &outputStream{output: o, dst: dst}
+	o.streams = append(o.streams, stream)
+	return stream
+}
+
+// note 只向日志文件写入一行说明，如每次执行的命令
+func (o *commandOutput) note(message string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.log != nil {
+		fmt.Fprintf(o.log, "%s ==== %s\n", time.Now().Format("2006-01-02 15:04:05.000"), message)
+	}
+}
+
+// Close 处理各输出流中最后未换行的内容并关闭日志文件，应在命令结束后调用
+func (o *commandOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, stream := range o.streams {
+		if len(stream.pending) > 0 {
+			o.writeLineUnsafe(stream.dst, stream.pending)
+			stream.pending = nil
+		}
+	}
+	if o.log == nil {
+		return nil
+	}
+	err := o.log.Close()
+	o.log = nil
+	return err
+}
+
+// decorated 判断是否需要为输出添加前缀，不需要时输出原样转发到终端
+func (o *commandOutput) decorated() bool {
+	return o.options.HasPrefix() || o.options.HasTimestamps()
+}
+
+// writeLineUnsafe 处理一行输出（内部使用）
+// 添加前缀时由此写入终端，否则终端输出已在写入时原样转发
+func (o *commandOutput) writeLineUnsafe(dst io.Writer, line []byte) {
+	now := time.Now()
+	text := strings.TrimSuffix(string(line), "\r")
+
+	var plain, colored string
+	if o.options.HasTimestamps() {
+		stamp := now.Format("15:04:05")
+		plain += stamp + " "
+		colored += ui.Gray + stamp + ui.Reset + " "
+	}
+	if o.options.HasPrefix() {
+		plain += o.name + " | "
+		colored += o.color + o.name + " |" + ui.Reset + " "
+	}
+
+	if o.decorated() {
+		io.WriteString(dst, colored+text+"\n")
+	}
+	o.lines.add(plain + text)
+	if o.log != nil {
+		fmt.Fprintf(o.log, "%s %s\n", now.Format("2006-01-02 15:04:05.000"), text)
+	}
+}
+
+// Write 按行处理输出，未换行的内容留到下一次写入或命令结束时处理
+func (s *outputStream) Write(p []byte) (int, error) {
+	o := s.output
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if !o.decorated() {
+		s.dst.Write(p)
+	}
+
+	data := p
+	if len(s.pending) > 0 {
+		data = append(s.pending, p...)
+	}
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		o.writeLineUnsafe(s.dst, data[:i])
+		data = data[i+1:]
+	}
+	if len(data) > maxOutputLine {
+		o.writeLineUnsafe(s.dst, data)
+		data = nil
+	}
+	s.pending = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// prefixColor 根据名称选择前缀颜色，同一名称每次的颜色相同
+func prefixColor(name string) string {
+	// 可用的颜色在调用时读取，禁用彩色输出后为空
+	colors := []string{ui.Cyan, ui.Purple, ui.Yellow, ui.Green, ui.Blue}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return colors[hash.Sum32()%uint32(len(colors))]
+}
+
+// logFileName 将命令名称转换为可用作文件名的日志文件名
+func logFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return safe + ".log"
+}
+
+// captureOutput 在转发命令输出的同时将其写入 w
+func captureOutput(cmd *exec.Cmd, w io.Writer) {
+	cmd.Stdout = io.MultiWriter(cmd.Stdout, w)
+	cmd.Stderr = io.MultiWriter(cmd.Stderr, w)
+}
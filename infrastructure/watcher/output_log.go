@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// maxLogFileSize 日志文件超过该大小后轮转
+	maxLogFileSize = 1 << 20
+	// logFileBackups 轮转后保留的旧日志文件数量
+	logFileBackups = 3
+)
+
+// rotatingLog 是超过大小上限后自动轮转的日志文件
+// 轮转时当前文件依次改名为 .1、.2……，只保留最近的几个
+type rotatingLog struct {
+	path string
+	file *os.File
+	size int64
+}
+
+// openRotatingLog 以追加方式打开日志文件，必要时创建所在目录
+func openRotatingLog(path string) (*rotatingLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	log := &rotatingLog{path: path}
+	if err := log.open(); err != nil {
+		return nil, err
+	}
+	return log, nil
+}
+
+// open 打开日志文件并记录其当前大小
+func (l *rotatingLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// Write 写入日志，写入后超过大小上限时先轮转
+func (l *rotatingLog) Write(p []byte) (int, error) {
+	if l.size > 0 && l.size+int64(len(p)) > maxLogFileSize {
+		if err := l.rotate(); err != nil {
+			return 0, fmt.Errorf("轮转日志文件失败: %w", err)
+		}
+	}
+	n, err := l.file.Write(p)
+	l.size += int64(n)
+	return n, err
+}
+
+// rotate 关闭当前文件，将日志文件依次后移一位后重新打开
+func (l *rotatingLog) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	for i := logFileBackups; i > 0; i-- {
+		from := l.path
+		if i > 1 {
+			from = fmt.Sprintf("%s.%d", l.path, i-1)
+		}
+		err := os.Rename(from, fmt.Sprintf("%s.%d", l.path, i))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return l.open()
+}
+
+// Close 关闭日志文件
+func (l *rotatingLog) Close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,267 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ignore"
+	"github.com/watchs/infrastructure/ui"
+)
+
+// defaultPollInterval 默认轮询间隔
+const defaultPollInterval = time.Second
+
+// fileState 记录轮询时文件的状态
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// PollWatcher 是基于定期扫描文件状态的文件监控服务实现
+// 适用于 inotify 等机制不可用的网络文件系统、容器挂载目录和 WSL 共享目录
+type PollWatcher struct {
+	config        *entity.WatchConfig
+	interval      time.Duration
+	ignore        *ignore.Matcher
+	snapshot      map[string]fileState
+	eventHandlers []func(event *entity.FileEvent) error
+	mu            sync.RWMutex
+	isRunning     bool
+	stopCh        chan struct{}
+	doneCh        chan struct{}
+	updateCh      chan configUpdate
+	skipped       skippedPaths
+}
+
+// NewPollWatcher 创建一个新的轮询文件监控器
+func NewPollWatcher(config *entity.WatchConfig) *PollWatcher {
+	return &PollWatcher{
+		config:    config,
+		interval:  pollInterval(config),
+		ignore:    newIgnoreMatcher(config),
+		isRunning: false,
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+		updateCh:  make(chan configUpdate),
+	}
+}
+
+// pollInterval 返回配置的轮询间隔，未配置时使用默认值
+func pollInterval(config *entity.WatchConfig) time.Duration {
+	if config.PollInterval <= 0 {
+		return defaultPollInterval
+	}
+	return config.PollInterval
+}
+
+// Start 开始监控文件
+func (w *PollWatcher) Start() error {
+	w.mu.Lock()
+	if w.isRunning {
+		w.mu.Unlock()
+		return nil
+	}
+	w.isRunning = true
+	w.mu.Unlock()
+
+	// 建立初始快照
+	snapshot, err := w.scan()
+	if err != nil {
+		w.mu.Lock()
+		w.isRunning = false
+		w.mu.Unlock()
+		return fmt.Errorf("扫描监控目录失败: %w", err)
+	}
+	w.snapshot = snapshot
+
+	ui.PrintSuccess(fmt.Sprintf("开始监控目录: %s (轮询模式，间隔 %v)", w.config.WatchDir, w.interval))
+	printWatchInfo(w.config)
+
+	go w.poll()
+
+	return nil
+}
+
+// Stop 停止监控
+func (w *PollWatcher) Stop() error {
+	w.mu.Lock()
+	if !w.isRunning {
+		w.mu.Unlock()
+		return nil
+	}
+	w.isRunning = false
+	w.mu.Unlock()
+
+	// 发送停止信号并等待goroutine退出（不持有锁，轮询goroutine需要读取处理函数）
+	close(w.stopCh)
+	<-w.doneCh
+
+	return nil
+}
+
+// OnFileEvent 注册文件事件处理函数
+func (w *PollWatcher) OnFileEvent(handler func(event *entity.FileEvent) error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.eventHandlers = append(w.eventHandlers, handler)
+}
+
+// UpdateConfig 切换到新的配置，下一次扫描起按新的监控目录和过滤规则比较快照
+func (w *PollWatcher) UpdateConfig(config *entity.WatchConfig) error {
+	w.mu.RLock()
+	running := w.isRunning
+	w.mu.RUnlock()
+
+	if !running {
+		w.config = config
+		w.interval = pollInterval(config)
+		w.ignore = newIgnoreMatcher(config)
+		return nil
+	}
+	return sendConfigUpdate(w.updateCh, w.stopCh, config)
+}
+
+// poll 定期扫描并比较快照
+func (w *PollWatcher) poll() {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case update := <-w.updateCh:
+			// 先按旧配置比较一次快照，切换前发生的变更不会丢失
+			w.check()
+			err := w.applyConfig(update.config)
+			if err == nil {
+				ticker.Reset(w.interval)
+			}
+			update.done <- err
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check 扫描监控目录并通知与上一次快照相比的变更
+func (w *PollWatcher) check() {
+	snapshot, err := w.scan()
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("监控错误: %v", err))
+		return
+	}
+	events := w.diff(w.snapshot, snapshot)
+	w.snapshot = snapshot
+
+	w.mu.RLock()
+	handlers := w.eventHandlers
+	w.mu.RUnlock()
+
+	for _, fileEvent := range events {
+		ui.PrintEvent(fileEvent)
+		notifyHandlers(handlers, fileEvent)
+	}
+}
+
+// applyConfig 切换配置并按新配置重新建立快照，扫描失败时保留旧配置
+func (w *PollWatcher) applyConfig(config *entity.WatchConfig) error {
+	oldConfig, oldIgnore := w.config, w.ignore
+	w.config = config
+	w.ignore = newIgnoreMatcher(config)
+
+	snapshot, err := w.scan()
+	if err != nil {
+		w.config, w.ignore = oldConfig, oldIgnore
+		return fmt.Errorf("扫描监控目录失败: %w", err)
+	}
+	w.snapshot = snapshot
+	w.interval = pollInterval(config)
+	printWatchInfo(config)
+	return nil
+}
+
+// scan 遍历监控目录，记录所有未被排除的文件和目录的状态
+func (w *PollWatcher) scan() (map[string]fileState, error) {
+	snapshot := make(map[string]fileState)
+
+	err := filepath.Walk(w.config.WatchDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			// 监控目录本身无法访问时扫描失败，其中无法访问的文件和目录跳过
+			if path == w.config.WatchDir {
+				return err
+			}
+			// 扫描过程中被删除的文件直接跳过
+			if !os.IsNotExist(err) {
+				w.skipped.warn(path, err)
+			}
+			return nil
+		}
+
+		if path != w.config.WatchDir {
+			if info.IsDir() && w.config.ShouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			if w.ignore.Match(path, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		snapshot[path] = fileState{
+			modTime: info.ModTime(),
+			size:    info.Size(),
+			isDir:   info.IsDir(),
+		}
+		return nil
+	})
+
+	return snapshot, err
+}
+
+// diff 比较两次快照，生成按路径排序的文件事件
+func (w *PollWatcher) diff(previous, current map[string]fileState) []*entity.FileEvent {
+	var events []*entity.FileEvent
+
+	for path, state := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			events = append(events, entity.NewFileEvent(path, entity.EventCreate))
+		case !state.isDir && (!state.modTime.Equal(old.modTime) || state.size != old.size):
+			events = append(events, entity.NewFileEvent(path, entity.EventWrite))
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			events = append(events, entity.NewFileEvent(path, entity.EventRemove))
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+
+	// 忽略文件变化后重新加载该目录的规则，并过滤不需要监控的文件
+	filtered := events[:0]
+	for _, event := range events {
+		if w.ignore.IsIgnoreFile(event.Path) {
+			w.ignore.Invalidate(filepath.Dir(event.Path))
+		}
+		if w.config.ShouldWatch(event.Path) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
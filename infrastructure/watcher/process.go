@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ui"
+)
+
+const (
+	// defaultKillSignal 默认的终止信号
+	defaultKillSignal = "SIGTERM"
+	// defaultGracePeriod 默认的终止宽限期
+	defaultGracePeriod = 5 * time.Second
+)
+
+// stopProcess 向命令的进程组发送终止信号，超过宽限期仍未退出则强制结束整个进程组
+// doneCh 在进程被回收后关闭，函数返回时进程已经退出
+func stopProcess(cmd *exec.Cmd, doneCh <-chan struct{}, options entity.CommandOptions) error {
+	signal := defaultKillSignal
+	if options.KillSignal != "" {
+		signal, _ = entity.NormalizeSignal(options.KillSignal)
+	}
+	grace := options.GracePeriod
+	if grace <= 0 {
+		grace = defaultGracePeriod
+	}
+
+	// 无法发送信号时（如 Windows 上的控制台程序）直接强制结束
+	if err := signalProcessGroup(cmd, signal); err != nil {
+		err = killProcessGroup(cmd)
+		<-doneCh
+		return err
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+
+	select {
+	case <-doneCh:
+		return nil
+	case <-timer.C:
+		ui.PrintWarning(fmt.Sprintf("进程未在 %v 内退出，强制终止", grace))
+		err := killProcessGroup(cmd)
+		<-doneCh
+		return err
+	}
+}
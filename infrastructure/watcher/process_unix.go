@@ -0,0 +1,65 @@
+//go:build !windows
+
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// signalsByName 信号名称与 Unix 信号的对应关系
+var signalsByName = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// setProcessGroup 让命令在独立的进程组中运行，使 shell 启动的子进程可以一起被终止
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcessGroup 向命令所在的整个进程组发送信号
+func signalProcessGroup(cmd *exec.Cmd, name string) error {
+	sig, ok := signalsByName[name]
+	if !ok {
+		return fmt.Errorf("不支持的信号: %s", name)
+	}
+	return syscall.Kill(-cmd.Process.Pid, sig)
+}
+
+// reloadProcessGroup 向命令所在的进程组发送重新加载信号
+func reloadProcessGroup(cmd *exec.Cmd, name string) error {
+	return signalProcessGroup(cmd, name)
+}
+
+// killProcessGroup 强制结束命令所在的整个进程组
+func killProcessGroup(cmd *exec.Cmd) error {
+	err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	if err == syscall.ESRCH {
+		// 进程组已经不存在
+		return nil
+	}
+	return err
+}
+
+// exitSignal 返回终止进程的信号名称，进程正常退出时返回空字符串
+func exitSignal(state *os.ProcessState) string {
+	status, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return ""
+	}
+	sig := status.Signal()
+	for name, s := range signalsByName {
+		if s == sig {
+			return name
+		}
+	}
+	return sig.String()
+}
@@ -0,0 +1,35 @@
+//go:build windows
+
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup Windows 上通过 taskkill /T 终止进程树，无需额外设置
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup Windows 不支持 Unix 信号，使用不带 /F 的 taskkill 请求进程树退出
+// 控制台程序通常无法响应该请求，此时返回错误，由调用方强制结束
+func signalProcessGroup(cmd *exec.Cmd, name string) error {
+	return exec.Command("taskkill", "/T", "/PID", fmt.Sprintf("%d", cmd.Process.Pid)).Run()
+}
+
+// reloadProcessGroup Windows 不支持 Unix 信号，无法通知进程重新加载，由调用方改为重启命令
+func reloadProcessGroup(cmd *exec.Cmd, name string) error {
+	return fmt.Errorf("Windows 不支持发送 %s 信号", name)
+}
+
+// killProcessGroup 使用 taskkill 强制结束进程树
+func killProcessGroup(cmd *exec.Cmd) error {
+	// 忽略taskkill的错误，因为进程可能已经结束
+	exec.Command("taskkill", "/F", "/T", "/PID", fmt.Sprintf("%d", cmd.Process.Pid)).Run()
+	return nil
+}
+
+// exitSignal Windows 上进程不会被信号终止，始终返回空字符串
+func exitSignal(state *os.ProcessState) string {
+	return ""
+}
@@ -0,0 +1,75 @@
+//go:build !windows
+
+package watcher
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// startPTY 在伪终端中启动命令，命令的标准输出和标准错误合并后写入原来的 cmd.Stdout
+// 伪终端的大小与 watchs 所在的终端保持一致，withInput 为 true 时返回伪终端用于写入输入
+func startPTY(cmd *exec.Cmd, withInput bool) (func() error, io.Writer, error) {
+	output := cmd.Stdout
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+
+	// 在新的会话中运行，会话首进程的进程组ID与其PID相同，仍可按进程组终止
+	// 标准输入可能是传递文件列表的管道，因此以标准输出作为控制终端
+	attrs := &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 1}
+	size, err := pty.GetsizeFull(os.Stdin)
+	if err != nil {
+		// watchs 的输入不是终端，使用伪终端的默认大小
+		size = nil
+	}
+	ptmx, err := pty.StartWithAttrs(cmd, size, attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(output, ptmx)
+		close(copied)
+	}()
+
+	// 终端大小变化时同步调整伪终端
+	resized := make(chan os.Signal, 1)
+	signal.Notify(resized, syscall.SIGWINCH)
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-resized:
+				pty.InheritSize(os.Stdin, ptmx)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	wait := func() error {
+		err := cmd.Wait()
+		signal.Stop(resized)
+		close(stop)
+
+		// 后台子进程可能仍持有伪终端，最多等待 outputWaitDelay 后停止转发
+		select {
+		case <-copied:
+		case <-time.After(outputWaitDelay):
+		}
+		ptmx.Close()
+		<-copied
+		return err
+	}
+	if !withInput {
+		return wait, nil, nil
+	}
+	return wait, ptmx, nil
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package watcher
+
+import (
+	"io"
+	"os/exec"
+	"sync"
+
+	"github.com/watchs/infrastructure/ui"
+)
+
+// ptyWarning 保证 Windows 上只提示一次不支持伪终端
+var ptyWarning sync.Once
+
+// startPTY Windows 不支持伪终端，提示后按普通方式启动命令
+func startPTY(cmd *exec.Cmd, withInput bool) (func() error, io.Writer, error) {
+	ptyWarning.Do(func() {
+		ui.PrintWarning("Windows 不支持 pty 模式，命令将以普通方式运行")
+	})
+	return startPlain(cmd, withInput)
+}
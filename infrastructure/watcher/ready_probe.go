@@ -0,0 +1,159 @@
+package watcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/watchs/domain/entity"
+)
+
+const (
+	// defaultReadyTimeout 默认的等待就绪时间
+	defaultReadyTimeout = 30 * time.Second
+	// defaultReadyInterval 默认的 HTTP 和 TCP 检查间隔
+	defaultReadyInterval = 500 * time.Millisecond
+	// readyRequestTimeout 单次 HTTP 请求或 TCP 连接的超时时间
+	readyRequestTimeout = 2 * time.Second
+	// maxLogLine 日志检查时缓存的最大行长度
+	maxLogLine = 64 * 1024
+)
+
+// errExitedBeforeReady 命令在通过就绪检查前退出
+var errExitedBeforeReady = errors.New("命令在就绪前已退出")
+
+// logMatcher 在命令输出中逐行查找匹配正则表达式的内容，找到后关闭 matched
+type logMatcher struct {
+	pattern *regexp.Regexp
+	mu      sync.Mutex
+	line    []byte
+	found   bool
+	matched chan struct{}
+}
+
+// newLogMatcher 创建日志匹配器
+func newLogMatcher(pattern *regexp.Regexp) *logMatcher {
+	return &logMatcher{
+		pattern: pattern,
+		matched: make(chan struct{}),
+	}
+}
+
+// Write 按行匹配输出，尚未换行的内容（如提示符）同样参与匹配
+func (m *logMatcher) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.found {
+		return len(p), nil
+	}
+
+	data := p
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		m.line = append(m.line, data[:i]...)
+		if m.matchUnsafe() {
+			return len(p), nil
+		}
+		m.line = m.line[:0]
+		data = data[i+1:]
+	}
+
+	m.line = append(m.line, data...)
+	if len(m.line) > maxLogLine {
+		m.line = append(m.line[:0], m.line[len(m.line)-maxLogLine:]...)
+	}
+	m.matchUnsafe()
+	return len(p), nil
+}
+
+// matchUnsafe 检查当前行是否匹配，匹配时关闭 matched（内部使用）
+func (m *logMatcher) matchUnsafe() bool {
+	if len(m.line) == 0 || !m.pattern.Match(m.line) {
+		return false
+	}
+	m.found = true
+	m.line = nil
+	close(m.matched)
+	return true
+}
+
+// waitReady 等待配置的所有就绪检查通过，命令退出或超过等待时间时返回错误
+// logs 为 nil 表示未配置日志检查
+func waitReady(probe *entity.ReadyProbe, logs *logMatcher, exited <-chan struct{}) error {
+	timeout := probe.Timeout
+	if timeout <= 0 {
+		timeout = defaultReadyTimeout
+	}
+	interval := probe.Interval
+	if interval <= 0 {
+		interval = defaultReadyInterval
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	if logs != nil {
+		select {
+		case <-logs.matched:
+		case <-exited:
+			return errExitedBeforeReady
+		case <-deadline.C:
+			return fmt.Errorf("等待就绪超时 (%v)，输出中没有匹配 %q 的内容", timeout, probe.LogPattern)
+		}
+	}
+
+	if probe.HTTP == "" && probe.TCP == "" {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := checkEndpoints(probe)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-exited:
+			return errExitedBeforeReady
+		case <-deadline.C:
+			return fmt.Errorf("等待就绪超时 (%v): %w", timeout, err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkEndpoints 检查 HTTP 地址和 TCP 端口
+func checkEndpoints(probe *entity.ReadyProbe) error {
+	if probe.TCP != "" {
+		conn, err := net.DialTimeout("tcp", probe.TCP, readyRequestTimeout)
+		if err != nil {
+			return fmt.Errorf("TCP 检查失败: %w", err)
+		}
+		conn.Close()
+	}
+
+	if probe.HTTP != "" {
+		client := &http.Client{Timeout: readyRequestTimeout}
+		resp, err := client.Get(probe.HTTP)
+		if err != nil {
+			return fmt.Errorf("HTTP 检查失败: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("HTTP 检查失败: 状态码 %d", resp.StatusCode)
+		}
+	}
+	return nil
+}
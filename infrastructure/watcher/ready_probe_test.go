@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/watchs/domain/entity"
+)
+
+// TestWaitReadyHTTPSuccess 服务在几次失败后返回 2xx 时就绪检查通过
+func TestWaitReadyHTTPSuccess(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	probe := &entity.ReadyProbe{HTTP: server.URL, Timeout: 5 * time.Second, Interval: 10 * time.Millisecond}
+	if err := waitReady(probe, nil, make(chan struct{})); err != nil {
+		t.Fatalf("waitReady() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+// TestWaitReadyHTTPNon2xx 服务一直返回非 2xx 时等待超时，错误中包含状态码
+func TestWaitReadyHTTPNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	probe := &entity.ReadyProbe{HTTP: server.URL, Timeout: 100 * time.Millisecond, Interval: 10 * time.Millisecond}
+	err := waitReady(probe, nil, make(chan struct{}))
+	if err == nil {
+		t.Fatal("waitReady() error = nil, want timeout")
+	}
+	if !strings.Contains(err.Error(), "等待就绪超时") || !strings.Contains(err.Error(), "状态码 500") {
+		t.Errorf("waitReady() error = %q, want timeout with status code 500", err)
+	}
+}
+
+// TestWaitReadyTimeout 端口一直无法连接时在超时后返回错误
+func TestWaitReadyTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	probe := &entity.ReadyProbe{TCP: addr, Timeout: 100 * time.Millisecond, Interval: 10 * time.Millisecond}
+	started := time.Now()
+	err = waitReady(probe, nil, make(chan struct{}))
+	if err == nil || !strings.Contains(err.Error(), "等待就绪超时") {
+		t.Fatalf("waitReady() error = %v, want timeout", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("waitReady() took %v, want about 100ms", elapsed)
+	}
+}
+
+// TestWaitReadyExited 命令在就绪前退出时立即返回
+func TestWaitReadyExited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	exited := make(chan struct{})
+	close(exited)
+	probe := &entity.ReadyProbe{HTTP: server.URL, Timeout: 5 * time.Second, Interval: 10 * time.Millisecond}
+	if err := waitReady(probe, nil, exited); !errors.Is(err, errExitedBeforeReady) {
+		t.Fatalf("waitReady() error = %v, want %v", err, errExitedBeforeReady)
+	}
+}
+
+// TestWaitReadyLog 输出中出现匹配的内容后就绪，跨多次写入的行也能匹配
+func TestWaitReadyLog(t *testing.T) {
+	logs := newLogMatcher(regexp.MustCompile(`listening on :\d+`))
+	logs.Write([]byte("starting\nlisten"))
+	logs.Write([]byte("ing on :8080\n"))
+
+	probe := &entity.ReadyProbe{LogPattern: `listening on :\d+`, Timeout: time.Second}
+	if err := waitReady(probe, logs, make(chan struct{})); err != nil {
+		t.Fatalf("waitReady() error = %v", err)
+	}
+}
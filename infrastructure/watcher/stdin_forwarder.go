@@ -0,0 +1,143 @@
+package watcher
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/watchs/infrastructure/ui"
+)
+
+// stdinQueueSize 等待写入命令的输入行数上限，命令不读取输入时超出的行被丢弃
+const stdinQueueSize = 64
+
+// StdinForwarder 将 watchs 的标准输入按行转发给正在运行的命令
+// 以转义前缀开头的行由 watchs 自己处理：前缀后跟 r 立即重新执行命令，跟 q 停止监控，
+// 连续两个前缀表示将去掉一个前缀后的内容转发给命令
+// 终端保持行模式，输入在按下回车后才会转发，不支持逐键交互
+type StdinForwarder struct {
+	escape string
+	rerun  func()
+	quit   func()
+	mu     sync.Mutex
+	// 当前接收输入的命令，没有正在运行的命令时为 nil
+	target io.Writer
+	// 等待写入命令的输入，由单独的 goroutine 写入，命令不读取输入时不会阻塞转义命令的处理
+	lines chan stdinLine
+}
+
+// stdinLine 是一行等待写入命令的输入
+type stdinLine struct {
+	target io.Writer
+	text   string
+}
+
+// NewStdinForwarder 创建标准输入转发器，rerun 和 quit 分别在输入对应的转义命令时调用
+func NewStdinForwarder(escape string, rerun func(), quit func()) *StdinForwarder {
+	return &StdinForwarder{
+		escape: escape,
+		rerun:  rerun,
+		quit:   quit,
+		lines:  make(chan stdinLine, stdinQueueSize),
+	}
+}
+
+// Start 在后台读取标准输入，直到输入结束
+func (f *StdinForwarder) Start() {
+	ui.PrintInfo(fmt.Sprintf("输入将转发给正在运行的命令，输入 %s? 查看 watchs 的命令", f.escape))
+	go f.write()
+	go f.run(os.Stdin)
+}
+
+// run 逐行读取输入并处理，输入结束后停止写入
+func (f *StdinForwarder) run(r io.Reader) {
+	defer close(f.lines)
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			f.handleLine(line)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleLine 处理转义命令，其余输入转发给命令
+func (f *StdinForwarder) handleLine(line string) {
+	rest, escaped := strings.CutPrefix(line, f.escape)
+	if escaped {
+		if !strings.HasPrefix(rest, f.escape) {
+			f.handleEscape(strings.TrimRight(rest, "\r\n"))
+			return
+		}
+		line = rest
+	}
+
+	f.mu.Lock()
+	target := f.target
+	f.mu.Unlock()
+
+	if target == nil {
+		ui.PrintWarning("没有正在运行的命令，输入已丢弃")
+		return
+	}
+	select {
+	case f.lines <- stdinLine{target: target, text: line}:
+	default:
+		ui.PrintWarning("命令没有读取输入，输入已丢弃")
+	}
+}
+
+// write 将输入依次写入命令，不持有锁写入，命令不读取输入时不会阻塞命令的启动和结束
+// 写入前命令已经结束的输入被丢弃
+func (f *StdinForwarder) write() {
+	for line := range f.lines {
+		f.mu.Lock()
+		current := f.target
+		f.mu.Unlock()
+
+		if current != line.target {
+			ui.PrintWarning("命令已结束，输入已丢弃")
+			continue
+		}
+		if _, err := io.WriteString(line.target, line.text); err != nil {
+			ui.PrintWarning(fmt.Sprintf("转发输入失败: %v", err))
+		}
+	}
+}
+
+// handleEscape 执行转义命令
+func (f *StdinForwarder) handleEscape(command string) {
+	switch command {
+	case "r":
+		f.rerun()
+	case "q":
+		f.quit()
+	default:
+		ui.PrintInfo(fmt.Sprintf("watchs 命令: %[1]sr 重新执行命令，%[1]sq 停止监控，%[1]s%[1]s 发送以 %[1]s 开头的输入", f.escape))
+	}
+}
+
+// attach 将之后的输入转发给新启动的命令
+func (f *StdinForwarder) attach(w io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.target = w
+}
+
+// detach 在命令结束后停止向其转发输入，w 已被新命令替换时不做处理
+func (f *StdinForwarder) detach(w io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.target == w {
+		f.target = nil
+	}
+}
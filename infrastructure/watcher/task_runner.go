@@ -0,0 +1,320 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ui"
+)
+
+// TaskRunner 按依赖关系执行任务流水线的命令执行器
+// 没有依赖关系的任务并行执行，任一任务失败时取消整个流水线（fail-fast）
+type TaskRunner struct {
+	tasks          []entity.Task
+	options        entity.CommandOptions
+	resultHandlers []func(result *entity.RunResult)
+	mu             sync.Mutex
+	cancel         context.CancelFunc
+	doneCh         chan struct{}
+	pending        *runRequest
+}
+
+// taskResult 记录任务在一次流水线执行中的结果
+type taskResult struct {
+	status   entity.TaskStatus
+	exitCode int
+	doneCh   chan struct{}
+}
+
+// NewTaskRunner 创建任务流水线执行器
+func NewTaskRunner(tasks []entity.Task, options entity.CommandOptions) (*TaskRunner, error) {
+	sorted, err := entity.SortTasks(tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskRunner{
+		tasks:   sorted,
+		options: options,
+	}, nil
+}
+
+// Execute 执行任务流水线，command 参数不使用
+// 上一次流水线仍在执行时，按 on_busy 策略取消重跑、排队或忽略
+func (r *TaskRunner) Execute(command string, workDir string, events []*entity.FileEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.isBusyUnsafe() {
+		switch r.options.OnBusy {
+		case entity.BusyIgnore:
+			ui.PrintInfo("任务流水线仍在执行，忽略本次变更")
+			return nil
+		case entity.BusyQueue:
+			if r.pending == nil {
+				r.pending = &runRequest{command: command, workDir: workDir}
+			}
+			r.pending.events = append(r.pending.events, events...)
+			ui.PrintInfo("任务流水线仍在执行，完成后将再执行一次")
+			return nil
+		}
+	}
+
+	r.terminateUnsafe()
+	r.startUnsafe(workDir, events)
+	return nil
+}
+
+// Terminate 取消正在执行的流水线
+func (r *TaskRunner) Terminate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.terminateUnsafe()
+}
+
+// Close 清理资源
+func (r *TaskRunner) Close() error {
+	return r.Terminate()
+}
+
+// OnRunComplete 注册流水线执行结束后的处理函数
+func (r *TaskRunner) OnRunComplete(handler func(result *entity.RunResult)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resultHandlers = append(r.resultHandlers, handler)
+}
+
+// OnReady 任务流水线不支持就绪检查，配置校验不允许同时设置 ready 和 tasks，处理函数不会被调用
+func (r *TaskRunner) OnReady(handler func(result *entity.RunResult)) {}
+
+// isBusyUnsafe 判断上一次流水线是否仍在执行（内部使用）
+func (r *TaskRunner) isBusyUnsafe() bool {
+	if r.doneCh == nil {
+		return false
+	}
+	select {
+	case <-r.doneCh:
+		return false
+	default:
+		return true
+	}
+}
+
+// startUnsafe 在不加锁的情况下在后台启动一次流水线（内部使用）
+func (r *TaskRunner) startUnsafe(workDir string, events []*entity.FileEvent) {
+	ctx, cancel := context.WithCancel(context.Background())
+	doneCh := make(chan struct{})
+	r.cancel = cancel
+	r.doneCh = doneCh
+
+	go func() {
+		result := r.run(ctx, cancel, newRunID(), workDir, events)
+		close(doneCh)
+
+		r.mu.Lock()
+		handlers := r.resultHandlers
+		r.mu.Unlock()
+		for _, handler := range handlers {
+			handler(result)
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		// 流水线已被取消或替换
+		if r.doneCh != doneCh || r.pending == nil {
+			return
+		}
+		next := r.pending
+		r.pending = nil
+		r.startUnsafe(next.workDir, next.events)
+	}()
+}
+
+// terminateUnsafe 在不加锁的情况下取消流水线并等待其结束（内部使用）
+func (r *TaskRunner) terminateUnsafe() error {
+	r.pending = nil
+
+	if r.cancel == nil {
+		return nil
+	}
+
+	r.cancel()
+	<-r.doneCh
+
+	r.cancel = nil
+	r.doneCh = nil
+	return nil
+}
+
+// run 执行一次完整的流水线，返回汇总的执行结果
+// 流水线的退出码为第一个失败任务的退出码
+func (r *TaskRunner) run(ctx context.Context, cancel context.CancelFunc, runID string, workDir string, events []*entity.FileEvent) *entity.RunResult {
+	defer cancel()
+
+	ui.PrintInfo(fmt.Sprintf("执行任务流水线 (%d 个任务)", len(r.tasks)))
+
+	names := make([]string, 0, len(r.tasks))
+	results := make(map[string]*taskResult, len(r.tasks))
+	for _, task := range r.tasks {
+		names = append(names, task.Name)
+		results[task.Name] = &taskResult{doneCh: make(chan struct{})}
+	}
+	runResult := entity.NewRunResult(runID, strings.Join(names, ", "), events)
+	lines := newLineBuffer(outputLines(r.options.Output))
+
+	var wg sync.WaitGroup
+	for _, task := range r.tasks {
+		wg.Add(1)
+		go func(task entity.Task) {
+			defer wg.Done()
+			result := results[task.Name]
+			defer close(result.doneCh)
+
+			result.status, result.exitCode = r.runTask(ctx, cancel, task, runID, workDir, events, results, lines)
+		}(task)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, task := range r.tasks {
+		result := results[task.Name]
+		if result.status != entity.TaskFailed && result.status != entity.TaskTimedOut {
+			continue
+		}
+		if len(failed) == 0 {
+			runResult.ExitCode = result.exitCode
+			runResult.TimedOut = result.status == entity.TaskTimedOut
+		}
+		failed = append(failed, task.Name)
+	}
+	runResult.Duration = time.Since(runResult.StartTime)
+	runResult.Output = lines.String()
+
+	elapsed := runResult.Duration.Round(time.Millisecond)
+	switch {
+	case len(failed) > 0:
+		runResult.Err = fmt.Errorf("任务失败: %s", strings.Join(failed, ", "))
+		ui.PrintError(fmt.Sprintf("任务流水线失败: %v (耗时 %v)", failed, elapsed))
+	case ctx.Err() != nil:
+		runResult.Terminated = true
+		ui.PrintWarning("任务流水线已取消")
+	default:
+		ui.PrintSuccess(fmt.Sprintf("任务流水线完成 (耗时 %v)", elapsed))
+	}
+	return runResult
+}
+
+// runCommand 执行命令并等待其结束，流水线被取消或命令超时时优雅地终止命令的进程组
+// 返回命令是否因超时被终止
+func (r *TaskRunner) runCommand(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) (bool, error) {
+	waitCmd, _, err := startCommand(cmd, r.options, false)
+	if err != nil {
+		return false, err
+	}
+
+	var waitErr error
+	doneCh := make(chan struct{})
+	go func() {
+		waitErr = waitCmd()
+		// 命令已成功退出，只是后台子进程仍持有输出管道
+		if errors.Is(waitErr, exec.ErrWaitDelay) {
+			waitErr = nil
+		}
+		close(doneCh)
+	}()
+
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+		stopProcess(cmd, doneCh, r.options)
+	case <-timeoutCh:
+		stopProcess(cmd, doneCh, r.options)
+		return true, waitErr
+	}
+	return false, waitErr
+}
+
+// runTask 等待依赖完成后执行单个任务，返回任务的最终状态和退出码
+func (r *TaskRunner) runTask(ctx context.Context, cancel context.CancelFunc, task entity.Task, runID string, workDir string, events []*entity.FileEvent, results map[string]*taskResult, lines *lineBuffer) (entity.TaskStatus, int) {
+	for _, dep := range task.DependsOn {
+		depSucceeded := false
+		select {
+		case <-results[dep].doneCh:
+			depSucceeded = results[dep].status == entity.TaskSucceeded
+		case <-ctx.Done():
+		}
+		if !depSucceeded {
+			ui.PrintTaskStatus(task.Name, entity.TaskSkipped, 0)
+			return entity.TaskSkipped, 0
+		}
+	}
+	if ctx.Err() != nil {
+		ui.PrintTaskStatus(task.Name, entity.TaskSkipped, 0)
+		return entity.TaskSkipped, 0
+	}
+
+	command, err := expandCommand(task.Command, workDir, events)
+	if err != nil {
+		ui.PrintTaskStatus(task.Name, entity.TaskFailed, 0)
+		ui.PrintError(fmt.Sprintf("任务 %s 执行失败: %v", task.Name, err))
+		cancel()
+		return entity.TaskFailed, -1
+	}
+
+	ui.PrintTaskStatus(task.Name, entity.TaskRunning, 0)
+	start := time.Now()
+
+	cmd := newShellCommand(context.Background(), command, workDir)
+	applyRunContext(cmd, runID, workDir, events, r.options)
+	output := newCommandOutput(task.Name, workDir, r.options.Output, lines)
+	output.attach(cmd)
+	output.note(fmt.Sprintf("执行任务: %s", command))
+	timeout := task.Timeout
+	if timeout <= 0 {
+		timeout = r.options.Timeout
+	}
+	timedOut, err := r.runCommand(ctx, cmd, timeout)
+	output.Close()
+	elapsed := time.Since(start)
+
+	exitCode := -1
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case timedOut:
+		ui.PrintTaskStatus(task.Name, entity.TaskTimedOut, elapsed)
+		ui.PrintError(fmt.Sprintf("任务 %s 执行超过 %v，已终止", task.Name, timeout))
+		cancel()
+		return entity.TaskTimedOut, exitCode
+	case err == nil:
+		ui.PrintTaskStatus(task.Name, entity.TaskSucceeded, elapsed)
+		return entity.TaskSucceeded, 0
+	case ctx.Err() != nil:
+		ui.PrintTaskStatus(task.Name, entity.TaskCanceled, elapsed)
+		return entity.TaskCanceled, exitCode
+	default:
+		ui.PrintTaskStatus(task.Name, entity.TaskFailed, elapsed)
+		ui.PrintError(fmt.Sprintf("任务 %s 执行失败: %v", task.Name, err))
+		// 快速失败：取消其他正在执行和等待中的任务
+		cancel()
+		return entity.TaskFailed, exitCode
+	}
+}
@@ -0,0 +1,54 @@
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match 判断以 / 分隔的相对路径 name 是否匹配 pattern
+// 每一段使用 path.Match 的语法，额外支持 ** 匹配零个或多个目录层级
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// ValidatePattern 检查模式语法是否正确
+func ValidatePattern(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchSegments 逐段匹配模式和路径
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			// 末尾的 ** 匹配其下的所有内容，但不匹配目录本身
+			if len(patterns) == 1 {
+				return len(names) > 0
+			}
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+		matched, err := path.Match(patterns[0], names[0])
+		if err != nil || !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
@@ -26,11 +26,13 @@ func (f *CLIFactory) CreateCLI() *cli.CLI {
 	cliInstance := cli.NewCLIWithRegistry(registry)
 
 	// 注册命令
-	registry.Register(cli.NewWatchCommand(f.container.GetWatchApplicationService()))
+	registry.Register(cli.NewWatchCommand(f.container.GetWatchApplicationService(), f.container.GetConfigPath()))
 	registry.Register(cli.NewInitCommand(f.container.GetConfigApplicationService()))
 	registry.Register(cli.NewInteractiveCommand(f.container.GetConfigApplicationService(), f.container.GetWatchApplicationService()))
 	registry.Register(cli.NewVersionCommand())
 	registry.Register(cli.NewMemoryCommand())
+	registry.Register(cli.NewHistoryCommand(f.container.GetHistoryApplicationService(), f.container.GetConfigApplicationService(), f.container.GetConfigPath()))
+	registry.Register(cli.NewValidateCommand(f.container.GetConfigApplicationService(), f.container.GetConfigPath()))
 
 	// 注册帮助命令（需要在其他命令注册后）
 	registry.Register(cli.NewHelpCommand(registry))
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/watchs/application/interfaces"
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ui"
+)
+
+// sinceLayouts --since 支持的时间格式（本地时区）
+var sinceLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// HistoryCommand 执行历史命令
+type HistoryCommand struct {
+	historyService interfaces.HistoryApplicationService
+	configService  interfaces.ConfigApplicationService
+	configPath     string
+}
+
+// NewHistoryCommand 创建执行历史命令，configPath 为默认使用的配置文件
+func NewHistoryCommand(historyService interfaces.HistoryApplicationService, configService interfaces.ConfigApplicationService, configPath string) *HistoryCommand {
+	return &HistoryCommand{
+		historyService: historyService,
+		configService:  configService,
+		configPath:     configPath,
+	}
+}
+
+// Name 返回命令名称
+func (c *HistoryCommand) Name() string {
+	return "history"
+}
+
+// Description 返回命令描述
+func (c *HistoryCommand) Description() string {
+	return "查看命令执行历史"
+}
+
+// Execute 执行命令
+func (c *HistoryCommand) Execute(args []string) error {
+	// 定义命令参数
+	historyCmd := flag.NewFlagSet("history", flag.ExitOnError)
+	configPath := historyCmd.String("config", c.configPath, "配置文件路径，执行历史保存在其监控目录的 .watchs 目录下")
+	profile := historyCmd.String("profile", os.Getenv("WATCHS_PROFILE"), "使用配置文件中的配置方案 (默认读取环境变量 WATCHS_PROFILE)")
+	dir := historyCmd.String("dir", "", "监控目录，覆盖配置文件中的 watch_dir")
+	failed := historyCmd.Bool("failed", false, "只显示失败和超时的执行")
+	since := historyCmd.String("since", "", "只显示该时间之后的执行，如 '24h'、'2024-05-01'、'2024-05-01 15:04'")
+	limit := historyCmd.Int("n", 20, "最多显示的记录数，0 表示全部")
+	help := historyCmd.Bool("help", false, "显示帮助信息")
+
+	// 解析参数
+	if err := historyCmd.Parse(args); err != nil {
+		return err
+	}
+
+	// 显示帮助信息
+	if *help {
+		ui.PrintHeader("命令执行历史")
+		fmt.Println("\n用法: watchs history [选项] [执行ID]")
+		fmt.Println("\n选项:")
+		historyCmd.PrintDefaults()
+		fmt.Println("\n示例:")
+		fmt.Println("  watchs history                      # 显示最近20次执行")
+		fmt.Println("  watchs history --failed             # 只显示失败的执行")
+		fmt.Println("  watchs history --since 24h          # 显示最近24小时的执行")
+		fmt.Println("  watchs history 20240501T150405-4242-3  # 显示某次执行的详情和输出")
+		return nil
+	}
+
+	watchDir, err := c.resolveWatchDir(*configPath, *profile, *dir)
+	if err != nil {
+		return err
+	}
+
+	// 指定了执行ID时显示详情
+	if historyCmd.NArg() > 0 {
+		result, err := c.historyService.GetRun(watchDir, historyCmd.Arg(0))
+		if err != nil {
+			return err
+		}
+		printRunDetail(result)
+		return nil
+	}
+
+	query := &interfaces.HistoryQuery{
+		WatchDir:   watchDir,
+		FailedOnly: *failed,
+		Limit:      *limit,
+	}
+	if *since != "" {
+		sinceTime, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		query.Since = sinceTime
+	}
+
+	results, err := c.historyService.ListRuns(query)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		ui.PrintInfo("没有符合条件的执行记录")
+		return nil
+	}
+
+	// 按时间从旧到新显示，最新的执行在最下方
+	for i := len(results) - 1; i >= 0; i-- {
+		printRunSummary(results[i])
+	}
+	return nil
+}
+
+// resolveWatchDir 返回执行历史所在的监控目录
+// 与 watch 命令一样加载配置文件和配置方案，--dir 覆盖其中的 watch_dir；没有配置文件时使用当前目录
+func (c *HistoryCommand) resolveWatchDir(configPath, profile, dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	config, err := c.configService.LoadOrCreateConfig(configPath, profile, "", "", "", "")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && profile == "" {
+			return "./", nil
+		}
+		return "", fmt.Errorf("配置加载失败: %w", err)
+	}
+	return config.WatchDir, nil
+}
+
+// parseSince 解析 --since 参数，支持相对时长和绝对时间
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	for _, layout := range sinceLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间 %q，应为时长（如 24h）或日期（如 2024-05-01）", value)
+}
+
+// runStatusStyle 返回执行结果对应的图标和颜色
+func runStatusStyle(result *entity.RunResult) (string, string) {
+	switch {
+	case result.Succeeded():
+		return ui.CheckMark, ui.Green
+	case result.Terminated:
+		return "⏹️", ui.Yellow
+	default:
+		return ui.CrossMark, ui.Red
+	}
+}
+
+// printRunSummary 以一行显示一次执行
+func printRunSummary(result *entity.RunResult) {
+	emoji, color := runStatusStyle(result)
+
+	status := result.Status()
+	if !result.Succeeded() && !result.Terminated && !result.TimedOut {
+		status = fmt.Sprintf("%s(%d)", status, result.ExitCode)
+	}
+
+	files := ""
+	if len(result.Files) > 0 {
+		files = fmt.Sprintf(" %s[%d 个文件]%s", ui.Gray, len(result.Files), ui.Reset)
+	}
+
+	fmt.Printf("%s %s  %s%s%s  %s%s%s  %v  %s%s\n",
+		emoji,
+		result.StartTime.Format("2006-01-02 15:04:05"),
+		ui.Gray, result.RunID, ui.Reset,
+		color, status, ui.Reset,
+		result.Duration,
+		result.Command,
+		files)
+}
+
+// printRunDetail 显示一次执行的详细信息和输出
+func printRunDetail(result *entity.RunResult) {
+	_, color := runStatusStyle(result)
+
+	ui.PrintHeader(fmt.Sprintf("执行记录 %s", result.RunID))
+	fmt.Printf("命令:     %s\n", result.Command)
+	fmt.Printf("开始时间: %s\n", result.StartTime.Format("2006-01-02 15:04:05"))
+	fmt.Printf("耗时:     %v\n", result.Duration)
+	fmt.Printf("状态:     %s%s%s\n", color, result.Status(), ui.Reset)
+	fmt.Printf("退出码:   %d\n", result.ExitCode)
+	if result.Signal != "" {
+		fmt.Printf("信号:     %s\n", result.Signal)
+	}
+	if result.Err != nil {
+		fmt.Printf("错误:     %v\n", result.Err)
+	}
+
+	if len(result.Files) > 0 {
+		fmt.Println("变更文件:")
+		for _, file := range result.Files {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+
+	if output := strings.TrimRight(result.Output, "\n"); output != "" {
+		fmt.Println("输出（末尾部分）:")
+		fmt.Println(output)
+	}
+}
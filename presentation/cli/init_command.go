@@ -34,7 +34,8 @@ func (c *InitCommand) Description() string {
 func (c *InitCommand) Execute(args []string) error {
 	// 定义命令参数
 	initCmd := flag.NewFlagSet("init", flag.ExitOnError)
-	configPath := initCmd.String("config", "watchs.json", "配置文件路径")
+	configPath := initCmd.String("config", "", "配置文件路径，默认为当前目录下的 watchs.<格式>")
+	format := initCmd.String("format", "json", "配置文件格式: json、yaml 或 toml，指定 -config 时按其扩展名决定")
 	watchDir := initCmd.String("dir", "./", "要监控的目录")
 	fileTypes := initCmd.String("types", "", "要监控的文件类型，以逗号分隔，如 '.go,.js'")
 	excludePaths := initCmd.String("exclude", "", "要排除的路径，以逗号分隔")
@@ -53,12 +54,24 @@ func (c *InitCommand) Execute(args []string) error {
 		fmt.Println("\n用法: watchs init [选项]")
 		fmt.Println("\n选项:")
 		initCmd.PrintDefaults()
+		fmt.Println("\n示例:")
+		fmt.Println("  watchs init -cmd \"go test ./...\"          # 生成 watchs.json")
+		fmt.Println("  watchs init -format yaml                  # 生成可以添加注释的 watchs.yaml")
+		fmt.Println("  watchs init -config ci/watchs.toml        # 按扩展名生成 TOML 配置")
 		return nil
 	}
 
+	path := *configPath
+	if path == "" {
+		var err error
+		if path, err = configFileForFormat(*format); err != nil {
+			return err
+		}
+	}
+
 	// 创建初始化参数
 	params := &interfaces.InitConfigParams{
-		ConfigPath:   *configPath,
+		ConfigPath:   path,
 		WatchDir:     *watchDir,
 		FileTypes:    *fileTypes,
 		ExcludePaths: *excludePaths,
@@ -69,3 +82,13 @@ func (c *InitCommand) Execute(args []string) error {
 	// 调用配置服务初始化配置
 	return c.configService.InitializeConfig(params)
 }
+
+// configFileForFormat 返回指定格式的默认配置文件名
+func configFileForFormat(format string) (string, error) {
+	switch format {
+	case "json", "yaml", "toml":
+		return "watchs." + format, nil
+	default:
+		return "", fmt.Errorf("无效的配置文件格式 %q，可选值: json、yaml、toml", format)
+	}
+}
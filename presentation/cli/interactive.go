@@ -29,8 +29,13 @@ func (cli *InteractiveCLI) Run() (*entity.WatchConfig, string, error) {
 	ui.PrintInfo("请回答以下问题来创建配置文件")
 	fmt.Println("----------------------------------------")
 
-	// 获取配置文件路径
-	configPath := cli.askString("配置文件路径", "watchs.json")
+	// 获取配置文件格式和路径
+	defaultPath, err := configFileForFormat(cli.askString("配置文件格式（json、yaml 或 toml）", "json"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, "", err
+	}
+	configPath := cli.askString("配置文件路径", defaultPath)
 
 	// 获取监控目录
 	watchDir := cli.askString("要监控的目录", "./")
@@ -69,6 +74,9 @@ func (cli *InteractiveCLI) Run() (*entity.WatchConfig, string, error) {
 
 	// 创建配置
 	config, err := entity.NewWatchConfig(absWatchDir, fileTypes, excludePaths, command)
+	if err == nil {
+		err = config.Validate()
+	}
 	if err != nil {
 		ui.PrintError(fmt.Sprintf("创建配置失败: %v", err))
 		return nil, "", err
@@ -82,7 +82,6 @@ func (c *InteractiveCommand) Execute(args []string) error {
 			FileTypes:      "",
 			ExcludePaths:   "",
 			Command:        "",
-			DebounceMs:     500,
 			ShowMemory:     enableMemory,
 			MemoryInterval: memoryInterval,
 		}
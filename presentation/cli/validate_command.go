@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/watchs/application/interfaces"
+	"github.com/watchs/domain/entity"
+	"github.com/watchs/infrastructure/ui"
+)
+
+// ValidateCommand 检查配置文件命令
+type ValidateCommand struct {
+	configService interfaces.ConfigApplicationService
+	configPath    string
+}
+
+// NewValidateCommand 创建检查配置文件命令，configPath 为未指定配置文件时检查的文件
+func NewValidateCommand(configService interfaces.ConfigApplicationService, configPath string) *ValidateCommand {
+	return &ValidateCommand{
+		configService: configService,
+		configPath:    configPath,
+	}
+}
+
+// Name 返回命令名称
+func (c *ValidateCommand) Name() string {
+	return "validate"
+}
+
+// Description 返回命令描述
+func (c *ValidateCommand) Description() string {
+	return "检查配置文件"
+}
+
+// Execute 执行命令
+func (c *ValidateCommand) Execute(args []string) error {
+	// 定义命令参数
+	validateCmd := flag.NewFlagSet("validate", flag.ExitOnError)
+	help := validateCmd.Bool("help", false, "显示帮助信息")
+
+	// 解析参数
+	if err := validateCmd.Parse(args); err != nil {
+		return err
+	}
+
+	// 显示帮助信息
+	if *help {
+		ui.PrintHeader("检查配置文件")
+		fmt.Println("\n用法: watchs validate [选项] [配置文件...]")
+		fmt.Println("\n未指定配置文件时检查当前目录或上级目录中的配置文件，发现问题时以非零状态码退出")
+		fmt.Println("\n选项:")
+		validateCmd.PrintDefaults()
+		fmt.Println("\n示例:")
+		fmt.Println("  watchs validate                          # 检查当前目录下的配置文件")
+		fmt.Println("  watchs validate watchs.yaml ci.toml      # 检查多个配置文件")
+		return nil
+	}
+
+	paths := validateCmd.Args()
+	if len(paths) == 0 {
+		paths = []string{c.configPath}
+	}
+
+	failed := 0
+	for _, path := range paths {
+		problems, err := c.configService.ValidateConfig(path)
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("%s: %v", path, err))
+			failed++
+			continue
+		}
+		if len(problems) == 0 {
+			ui.PrintSuccess(fmt.Sprintf("配置文件 %s 没有发现问题", path))
+			continue
+		}
+
+		for _, problem := range problems {
+			fmt.Printf("%s: %s\n", problemLocation(path, problem), problem)
+		}
+		ui.PrintError(fmt.Sprintf("配置文件 %s 中发现 %d 个问题", path, len(problems)))
+		failed++
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d 个配置文件未通过检查", failed)
+	}
+	return nil
+}
+
+// problemLocation 以 "文件:行:列" 的格式返回问题所在的位置，行列未知时省略
+func problemLocation(path string, problem entity.ConfigProblem) string {
+	if problem.Line == 0 {
+		return path
+	}
+	if problem.Column == 0 {
+		return fmt.Sprintf("%s:%d", path, problem.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", path, problem.Line, problem.Column)
+}
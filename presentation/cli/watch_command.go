@@ -3,6 +3,7 @@ package cli
 import (
 	"flag"
 	"fmt"
+	"os"
 
 	"github.com/watchs/application/interfaces"
 )
@@ -10,12 +11,14 @@ import (
 // WatchCommand 监控命令
 type WatchCommand struct {
 	watchService interfaces.WatchApplicationService
+	configPath   string
 }
 
-// NewWatchCommand 创建监控命令
-func NewWatchCommand(watchService interfaces.WatchApplicationService) *WatchCommand {
+// NewWatchCommand 创建监控命令，configPath 为默认使用的配置文件
+func NewWatchCommand(watchService interfaces.WatchApplicationService, configPath string) *WatchCommand {
 	return &WatchCommand{
 		watchService: watchService,
+		configPath:   configPath,
 	}
 }
 
@@ -33,12 +36,19 @@ func (c *WatchCommand) Description() string {
 func (c *WatchCommand) Execute(args []string) error {
 	// 定义命令参数
 	watchCmd := flag.NewFlagSet("watch", flag.ExitOnError)
-	configPath := watchCmd.String("config", "watchs.json", "配置文件路径")
+	configPath := watchCmd.String("config", c.configPath, "配置文件路径，支持 .json、.yaml 和 .toml")
+	profile := watchCmd.String("profile", os.Getenv("WATCHS_PROFILE"), "使用配置文件中的配置方案，如 dev、test、ci (默认读取环境变量 WATCHS_PROFILE)")
 	watchDir := watchCmd.String("dir", "", "要监控的目录 (覆盖配置文件)")
 	fileTypes := watchCmd.String("types", "", "要监控的文件类型，以逗号分隔，如 '.go,.js' (覆盖配置文件)")
 	excludePaths := watchCmd.String("exclude", "", "要排除的路径，以逗号分隔 (覆盖配置文件)")
 	command := watchCmd.String("cmd", "", "文件变化时执行的命令 (覆盖配置文件)")
-	debounceMs := watchCmd.Int("debounce", 500, "防抖时间（毫秒）")
+	debounceMs := watchCmd.Int("debounce", 0, "防抖时间（毫秒，覆盖配置文件，默认为500）")
+	backend := watchCmd.String("backend", "", "文件监控后端: auto、fsnotify 或 poll (覆盖配置文件)")
+	pollInterval := watchCmd.Int("poll-interval", 0, "轮询后端的扫描间隔（毫秒，覆盖配置文件）")
+	onBusy := watchCmd.String("on-busy", "", "命令仍在运行时的处理策略: restart、queue 或 ignore (覆盖配置文件)")
+	supervise := watchCmd.Bool("supervise", false, "命令意外退出时自动重启，适用于开发服务器等长时间运行的命令")
+	usePTY := watchCmd.Bool("pty", false, "在伪终端中运行命令，保留命令输出的颜色")
+	forwardStdin := watchCmd.Bool("stdin", false, "将输入转发给正在运行的命令，适用于 REPL 等交互式命令")
 	showMemory := watchCmd.Bool("memory", false, "显示内存使用信息")
 	memoryInterval := watchCmd.Int("memory-interval", 30, "内存信息显示间隔（秒）")
 	help := watchCmd.Bool("help", false, "显示帮助信息")
@@ -58,17 +68,30 @@ func (c *WatchCommand) Execute(args []string) error {
 		fmt.Println("  watchs watch                           # 使用默认配置监控")
 		fmt.Println("  watchs watch --memory                  # 监控时显示内存信息")
 		fmt.Println("  watchs watch --memory --memory-interval 60  # 每60秒显示内存信息")
+		fmt.Println("  watchs watch --profile test            # 使用配置文件中的 test 配置方案")
+		fmt.Println("  watchs watch --backend poll            # 在网络文件系统或容器挂载目录中使用轮询")
+		fmt.Println("  watchs watch --on-busy queue           # 不中断正在运行的命令，结束后再执行一次")
+		fmt.Println("  watchs watch --supervise -cmd \"go run .\"  # 服务崩溃后自动重启")
+		fmt.Println("  watchs watch --pty -cmd \"go test ./...\"  # 保留测试输出的颜色")
+		fmt.Println("  watchs watch --stdin -cmd \"npm run dev\"  # 可以向开发服务器输入快捷键")
 		return nil
 	}
 
 	// 创建监控配置参数
 	watchConfig := &interfaces.WatchConfig{
 		ConfigPath:     *configPath,
+		Profile:        *profile,
 		WatchDir:       *watchDir,
 		FileTypes:      *fileTypes,
 		ExcludePaths:   *excludePaths,
 		Command:        *command,
 		DebounceMs:     *debounceMs,
+		Backend:        *backend,
+		PollIntervalMs: *pollInterval,
+		OnBusy:         *onBusy,
+		Supervise:      *supervise,
+		PTY:            *usePTY,
+		ForwardStdin:   *forwardStdin,
 		ShowMemory:     *showMemory,
 		MemoryInterval: *memoryInterval,
 	}